@@ -19,14 +19,7 @@ import (
 // ProfilePictureUploadHandler handles the /profile/picture endpoint for uploading profile pictures
 func ProfilePictureUploadHandler(client *mongo.Client) http.HandlerFunc {
 	// Initialize AWS S3 uploader
-	awsSession, err := session.NewSession(&aws.Config{
-		Region: aws.String(os.Getenv("AWS_REGION")),
-		Credentials: credentials.NewStaticCredentials(
-			os.Getenv("AWS_ACCESS_KEY"),
-			os.Getenv("AWS_SECRET_ACCESS_KEY"),
-			"",
-		),
-	})
+	awsSession, err := newAWSSession()
 	if err != nil {
 		fmt.Printf("Failed to initialize AWS session: %v\n", err)
 	}
@@ -115,6 +108,18 @@ func ProfilePictureUploadHandler(client *mongo.Client) http.HandlerFunc {
 	}
 }
 
+// newAWSSession creates an AWS session from the credentials in the environment
+func newAWSSession() (*session.Session, error) {
+	return session.NewSession(&aws.Config{
+		Region: aws.String(os.Getenv("AWS_REGION")),
+		Credentials: credentials.NewStaticCredentials(
+			os.Getenv("AWS_ACCESS_KEY"),
+			os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			"",
+		),
+	})
+}
+
 // isImage checks if the content type is an image
 func isImage(contentType string) bool {
 	allowedTypes := []string{"image/jpeg", "image/png", "image/gif"}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	model "Backend-Auth-Profiles/models"
+	"Backend-Auth-Profiles/utils"
+)
+
+const defaultDeletionGraceDays = 30
+
+// DeleteAccountRequest defines the request structure for account deletion
+type DeleteAccountRequest struct {
+	Confirm string `json:"confirm"` // Must match the caller's channel_name
+}
+
+// deletionGracePeriod reads ACCOUNT_DELETION_GRACE_DAYS, falling back to the default
+func deletionGracePeriod() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS"))
+	if err != nil || days < 0 {
+		days = defaultDeletionGraceDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// DeleteAccountHandler handles DELETE /profile by scheduling the account for deletion
+func DeleteAccountHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req DeleteAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.Background()
+		user, err := findProfileByUserID(ctx, client, userID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if !strings.EqualFold(req.Confirm, user.ChannelName) {
+			writeJSONError(w, "Confirmation does not match your channel name", http.StatusBadRequest)
+			return
+		}
+
+		scheduledAt := time.Now().Add(deletionGracePeriod())
+		if user.DeletionScheduledAt != nil {
+			scheduledAt = *user.DeletionScheduledAt
+		}
+
+		collection := profilesCollection(client)
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{
+			"deletion_scheduled_at": scheduledAt,
+			"updated_at":            time.Now(),
+		}})
+		if err != nil {
+			writeJSONError(w, "Failed to schedule deletion", http.StatusInternalServerError)
+			return
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"deletion_scheduled_at": scheduledAt},
+			Message: "Account scheduled for deletion",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusAccepted)
+	}
+}
+
+// CancelAccountDeletionHandler handles the /profile/deletion/cancel endpoint
+func CancelAccountDeletionHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		collection := profilesCollection(client)
+		result, err := collection.UpdateOne(
+			context.Background(),
+			bson.M{"user_id": userID, "deletion_scheduled_at": bson.M{"$exists": true}},
+			bson.M{"$unset": bson.M{"deletion_scheduled_at": ""}, "$set": bson.M{"updated_at": time.Now()}},
+		)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if result.MatchedCount == 0 {
+			writeJSONError(w, "No pending deletion", http.StatusNotFound)
+			return
+		}
+
+		response := Response{
+			Message: "Account deletion cancelled",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// StartAccountDeletionWorker periodically deletes accounts whose grace period has elapsed.
+// Every instance ticks, and the job lease lets only one of them run each interval.
+func StartAccountDeletionWorker(client *mongo.Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			// The lease ends a little before the next tick so the following run is never locked out
+			acquired, err := acquireJobLease(context.Background(), client, "account-deletion", interval*9/10)
+			if err != nil {
+				log.Printf("Error acquiring account deletion lease: %v", err)
+			} else if acquired {
+				processDueDeletions(client)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+func processDueDeletions(client *mongo.Client) {
+	ctx := context.Background()
+	collection := profilesCollection(client)
+	cursor, err := collection.Find(ctx, bson.M{"deletion_scheduled_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		log.Printf("Error finding accounts due for deletion: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var users []model.User
+	if err := cursor.All(ctx, &users); err != nil {
+		log.Printf("Error decoding accounts due for deletion: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		if err := deleteAccount(ctx, client, user); err != nil {
+			log.Printf("Error deleting account %s: %v", user.ID.Hex(), err)
+		}
+	}
+}
+
+// deleteAccount removes the user and everything that references them across databases
+func deleteAccount(ctx context.Context, client *mongo.Client, user model.User) error {
+	if err := utils.RevokeAllSessions(user.UserID); err != nil {
+		return err
+	}
+
+	if _, err := client.Database("videos").Collection("upload").DeleteMany(ctx, bson.M{"profile._id": user.ID}); err != nil {
+		return err
+	}
+	if _, err := client.Database("myspace").Collection("rooms").DeleteMany(ctx, bson.M{"creator._id": user.ID}); err != nil {
+		return err
+	}
+
+	if err := deleteS3Prefix("display-pictures/dp_" + user.UserID + "_"); err != nil {
+		return err
+	}
+	if err := deleteS3Prefix("exports/" + user.ID.Hex() + "/"); err != nil {
+		return err
+	}
+	if err := deleteS3Prefix("verification-documents/" + user.ID.Hex() + "/"); err != nil {
+		return err
+	}
+
+	if err := deleteFollowEdges(ctx, client, user.ID); err != nil {
+		return err
+	}
+	requests := bson.M{"$or": bson.A{bson.M{"follower_id": user.ID}, bson.M{"followee_id": user.ID}}}
+	if _, err := followRequestsCollection(client).DeleteMany(ctx, requests); err != nil {
+		return err
+	}
+	relations := bson.M{"$or": bson.A{bson.M{"profile_id": user.ID}, bson.M{"target_id": user.ID}}}
+	if _, err := blocksCollection(client).DeleteMany(ctx, relations); err != nil {
+		return err
+	}
+	if _, err := mutesCollection(client).DeleteMany(ctx, relations); err != nil {
+		return err
+	}
+
+	if _, err := client.Database("authdb").Collection("auth_activity").DeleteMany(ctx, bson.M{"user_id": user.UserID}); err != nil {
+		return err
+	}
+	if _, err := exportsCollection(client).DeleteMany(ctx, bson.M{"user_id": user.UserID}); err != nil {
+		return err
+	}
+	if _, err := client.Database("authdb").Collection("verification_applications").DeleteMany(ctx, bson.M{"user_id": user.UserID}); err != nil {
+		return err
+	}
+
+	_, err := profilesCollection(client).DeleteOne(ctx, bson.M{"_id": user.ID})
+	return err
+}
+
+// deleteS3Prefix removes every object under prefix from the bucket
+func deleteS3Prefix(prefix string) error {
+	awsSession, err := newAWSSession()
+	if err != nil {
+		return err
+	}
+	svc := s3.New(awsSession)
+	bucket := aws.String(os.Getenv("AWS_BUCKET"))
+
+	var deleteErr error
+	err = svc.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: bucket,
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		if len(page.Contents) == 0 {
+			return !lastPage
+		}
+		var objects []*s3.ObjectIdentifier
+		for _, obj := range page.Contents {
+			objects = append(objects, &s3.ObjectIdentifier{Key: obj.Key})
+		}
+		_, deleteErr = svc.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: bucket,
+			Delete: &s3.Delete{Objects: objects},
+		})
+		return deleteErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return deleteErr
+}
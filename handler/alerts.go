@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	model "Backend-Auth-Profiles/models"
+	"Backend-Auth-Profiles/utils"
+)
+
+// AuthActivity is a single login event recorded in the auth_activity collection
+type AuthActivity struct {
+	UserID    string    `bson:"user_id" json:"user_id"`
+	Provider  string    `bson:"provider" json:"provider"`
+	DeviceID  string    `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	IP        string    `bson:"ip" json:"ip"`
+	UserAgent string    `bson:"user_agent" json:"user_agent"`
+	NewDevice bool      `bson:"new_device" json:"new_device"`
+	Anomalous bool      `bson:"anomalous" json:"anomalous"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// trustedProxies parses TRUSTED_PROXIES, a comma-separated list of proxy IPs or CIDRs whose
+// X-Forwarded-For header is believed. Without it the header is ignored, since any client can set it.
+func trustedProxies() []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if strings.Contains(entry, ":") {
+				entry += "/128"
+			} else {
+				entry += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("Ignoring invalid trusted proxy %q", entry)
+			continue
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies
+}
+
+func isTrustedProxy(addr string, proxies []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the caller address. X-Forwarded-For is only honoured when the request came
+// through a trusted proxy, and then the right-most hop that is not one of our proxies is the client.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	proxies := trustedProxies()
+	if !isTrustedProxy(host, proxies) {
+		return host
+	}
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !isTrustedProxy(hop, proxies) {
+			return hop
+		}
+		host = hop
+	}
+	return host
+}
+
+// differentNetwork reports whether two addresses fall outside a shared /16 (IPv4) or /48 (IPv6).
+// Address churn within one provider's range is routine for mobile and home connections, and
+// addresses of different families cannot be compared, so neither counts as a change.
+func differentNetwork(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a != b
+	}
+	v4A, v4B := ipA.To4(), ipB.To4()
+	switch {
+	case v4A != nil && v4B != nil:
+		mask := net.CIDRMask(16, 32)
+		return !v4A.Mask(mask).Equal(v4B.Mask(mask))
+	case v4A == nil && v4B == nil:
+		mask := net.CIDRMask(48, 128)
+		return !ipA.Mask(mask).Equal(ipB.Mask(mask))
+	}
+	return false
+}
+
+// detectLoginAnomaly compares a login against what we know about the existing user
+func detectLoginAnomaly(user model.User, deviceID, ip, userAgent string) (newDevice bool, anomalous bool) {
+	if deviceID != "" {
+		newDevice = true
+		for _, known := range user.DeviceIDList {
+			if known == deviceID {
+				newDevice = false
+				break
+			}
+		}
+	}
+	if user.LastLoginIP != "" && differentNetwork(user.LastLoginIP, ip) {
+		anomalous = true
+	}
+	if user.LastUserAgent != "" && user.LastUserAgent != userAgent {
+		anomalous = true
+	}
+	return newDevice, anomalous
+}
+
+// recordAuthActivity stores the login event and notifies the user when it looks unfamiliar
+func recordAuthActivity(client *mongo.Client, user model.User, activity AuthActivity) {
+	ctx := context.Background()
+	collection := client.Database("authdb").Collection("auth_activity")
+	if _, err := collection.InsertOne(ctx, activity); err != nil {
+		log.Printf("Error recording auth activity: %v", err)
+	}
+
+	if !activity.NewDevice && !activity.Anomalous {
+		return
+	}
+
+	revokeToken, err := utils.GenerateRevokeToken(user.UserID)
+	if err != nil {
+		log.Printf("Error generating revoke token: %v", err)
+		return
+	}
+
+	body := "We noticed a sign-in to your account from a new device."
+	if !activity.NewDevice {
+		body = "We noticed a sign-in to your account from an unusual location or browser."
+	}
+	body += " IP: " + activity.IP + ". If this wasn't you, sign out of all sessions now."
+
+	notification := utils.Notification{
+		UserID:   user.UserID,
+		Email:    user.Email,
+		FCMToken: user.FCMToken,
+		Title:    "New sign-in to your account",
+		Body:     body,
+		Link:     os.Getenv("APP_BASE_URL") + "/auth/revoke?token=" + url.QueryEscape(revokeToken),
+	}
+	if err := utils.Notify(ctx, notification); err != nil {
+		log.Printf("Error sending login alert: %v", err)
+	}
+}
@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UserRelation is a one-directional block or mute: ProfileID blocked or muted TargetID
+type UserRelation struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProfileID primitive.ObjectID `bson:"profile_id" json:"profile_id"`
+	TargetID  primitive.ObjectID `bson:"target_id" json:"target_id"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+func blocksCollection(client *mongo.Client) *mongo.Collection {
+	return client.Database("authdb").Collection("blocks")
+}
+
+func mutesCollection(client *mongo.Client) *mongo.Collection {
+	return client.Database("authdb").Collection("mutes")
+}
+
+// EnsureRelationIndexes creates the indexes for the blocks and mutes collections
+func EnsureRelationIndexes(client *mongo.Client) {
+	for _, collection := range []*mongo.Collection{blocksCollection(client), mutesCollection(client)} {
+		_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "profile_id", Value: 1}, {Key: "target_id", Value: 1}},
+				Options: options.Index().SetName("profile_target_unique").SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "target_id", Value: 1}},
+				Options: options.Index().SetName("target_id"),
+			},
+		})
+		if err != nil {
+			log.Printf("Error creating %s indexes: %v", collection.Name(), err)
+		}
+	}
+}
+
+// requireProfile answers 404 when id is not an existing profile, so blocks and mutes
+// cannot be recorded against made-up ids
+func requireProfile(w http.ResponseWriter, client *mongo.Client, id primitive.ObjectID) bool {
+	count, err := profilesCollection(client).CountDocuments(context.Background(), bson.M{"_id": id}, options.Count().SetLimit(1))
+	if err != nil {
+		writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+	if count == 0 {
+		writeJSONError(w, "User not found", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// isBlockedBetween reports whether either profile has blocked the other
+func isBlockedBetween(ctx context.Context, client *mongo.Client, a, b primitive.ObjectID) (bool, error) {
+	count, err := blocksCollection(client).CountDocuments(ctx, bson.M{"$or": bson.A{
+		bson.M{"profile_id": a, "target_id": b},
+		bson.M{"profile_id": b, "target_id": a},
+	}}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// blockedProfileIDs returns every profile that profileID blocked or was blocked by
+func blockedProfileIDs(ctx context.Context, client *mongo.Client, profileID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	cursor, err := blocksCollection(client).Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"profile_id": profileID},
+		bson.M{"target_id": profileID},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var relations []UserRelation
+	if err := cursor.All(ctx, &relations); err != nil {
+		return nil, err
+	}
+	ids := []primitive.ObjectID{}
+	for _, relation := range relations {
+		if relation.ProfileID == profileID {
+			ids = append(ids, relation.TargetID)
+		} else {
+			ids = append(ids, relation.ProfileID)
+		}
+	}
+	return ids, nil
+}
+
+// callerBlockedIDs resolves the optional caller of a public endpoint and returns the profiles hidden from them
+func callerBlockedIDs(ctx context.Context, r *http.Request, client *mongo.Client) ([]primitive.ObjectID, error) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		return nil, nil
+	}
+	caller, err := findProfileByUserID(ctx, client, userID)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return blockedProfileIDs(ctx, client, caller.ID)
+}
+
+// hiddenByBlock reports whether the optional caller of a public endpoint and targetID have blocked each other
+func hiddenByBlock(ctx context.Context, r *http.Request, client *mongo.Client, targetID primitive.ObjectID) (bool, error) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		return false, nil
+	}
+	caller, err := findProfileByUserID(ctx, client, userID)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return isBlockedBetween(ctx, client, caller.ID, targetID)
+}
+
+// MongoMuteChecker implements utils.MuteChecker against the mutes and blocks collections
+type MongoMuteChecker struct {
+	client *mongo.Client
+}
+
+func NewMongoMuteChecker(client *mongo.Client) *MongoMuteChecker {
+	return &MongoMuteChecker{client: client}
+}
+
+// Muted reports whether the user with the given token user id muted or blocked the actor profile
+func (m *MongoMuteChecker) Muted(ctx context.Context, userID, actorID string) (bool, error) {
+	actor, err := primitive.ObjectIDFromHex(actorID)
+	if err != nil {
+		return false, nil
+	}
+	recipient, err := findProfileByUserID(ctx, m.client, userID)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	filter := bson.M{"profile_id": recipient.ID, "target_id": actor}
+	count, err := mutesCollection(m.client).CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	return isBlockedBetween(ctx, m.client, recipient.ID, actor)
+}
+
+// BlockHandler handles POST /profile/{id}/block
+func BlockHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, targetID, ok := resolveFollowTarget(w, r, client)
+		if !ok {
+			return
+		}
+		if !requireProfile(w, client, targetID) {
+			return
+		}
+
+		ctx := context.Background()
+		_, err := blocksCollection(client).InsertOne(ctx, UserRelation{ProfileID: caller.ID, TargetID: targetID, CreatedAt: time.Now()})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			writeJSONError(w, "Failed to block user", http.StatusInternalServerError)
+			return
+		}
+
+		// Blocking severs the follow graph in both directions
+		for _, edge := range [][2]primitive.ObjectID{{caller.ID, targetID}, {targetID, caller.ID}} {
+			result, err := followsCollection(client).DeleteOne(ctx, bson.M{"follower_id": edge[0], "followee_id": edge[1]})
+			if err != nil {
+				writeJSONError(w, "Failed to block user", http.StatusInternalServerError)
+				return
+			}
+			if result.DeletedCount > 0 {
+				if err := adjustFollowCounts(ctx, client, edge[0], edge[1], -1); err != nil {
+					log.Printf("Error updating follow counts: %v", err)
+				}
+			}
+			if _, err := followRequestsCollection(client).DeleteOne(ctx, bson.M{"follower_id": edge[0], "followee_id": edge[1]}); err != nil {
+				writeJSONError(w, "Failed to block user", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"blocked": true},
+			Message: "User blocked",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// UnblockHandler handles DELETE /profile/{id}/block
+func UnblockHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, targetID, ok := resolveFollowTarget(w, r, client)
+		if !ok {
+			return
+		}
+
+		if _, err := blocksCollection(client).DeleteOne(context.Background(), bson.M{"profile_id": caller.ID, "target_id": targetID}); err != nil {
+			writeJSONError(w, "Failed to unblock user", http.StatusInternalServerError)
+			return
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"blocked": false},
+			Message: "User unblocked",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// MuteHandler handles POST /profile/{id}/mute
+func MuteHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, targetID, ok := resolveFollowTarget(w, r, client)
+		if !ok {
+			return
+		}
+		if !requireProfile(w, client, targetID) {
+			return
+		}
+
+		_, err := mutesCollection(client).InsertOne(context.Background(), UserRelation{ProfileID: caller.ID, TargetID: targetID, CreatedAt: time.Now()})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			writeJSONError(w, "Failed to mute user", http.StatusInternalServerError)
+			return
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"muted": true},
+			Message: "User muted",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// UnmuteHandler handles DELETE /profile/{id}/mute
+func UnmuteHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, targetID, ok := resolveFollowTarget(w, r, client)
+		if !ok {
+			return
+		}
+
+		if _, err := mutesCollection(client).DeleteOne(context.Background(), bson.M{"profile_id": caller.ID, "target_id": targetID}); err != nil {
+			writeJSONError(w, "Failed to unmute user", http.StatusInternalServerError)
+			return
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"muted": false},
+			Message: "User unmuted",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// ListRelationsHandler handles GET /profile/blocks and /profile/mutes; feed services use it to filter content
+func ListRelationsHandler(client *mongo.Client, collectionName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.Background()
+		caller, err := findProfileByUserID(ctx, client, userID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		cursor, err := client.Database("authdb").Collection(collectionName).Find(ctx,
+			bson.M{"profile_id": caller.ID},
+			options.Find().SetSort(bson.M{"created_at": -1}),
+		)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer cursor.Close(ctx)
+
+		var relations []UserRelation
+		if err := cursor.All(ctx, &relations); err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		ids := make([]primitive.ObjectID, 0, len(relations))
+		for _, relation := range relations {
+			ids = append(ids, relation.TargetID)
+		}
+		profiles, err := miniProfiles(ctx, client, ids)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		entries := []ProfileSuggestion{}
+		for _, id := range ids {
+			if profile, ok := profiles[id]; ok {
+				entries = append(entries, profile)
+			}
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"profiles": entries},
+			Message: "Profiles in " + collectionName,
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
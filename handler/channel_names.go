@@ -0,0 +1,365 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+	"Backend-Auth-Profiles/utils"
+)
+
+const (
+	channelNameRenameCooldown = 30 * 24 * time.Hour
+	channelNameHoldPeriod     = 90 * 24 * time.Hour
+	channelNameMaxAttempts    = 5
+)
+
+// channelNameSuffixDigits widens the random suffix on each attempt, so a crowded prefix such as
+// "user", which every name without enough ASCII letters falls back to, does not run out of names
+var channelNameSuffixDigits = []int{4, 4, 6, 6, 8}
+
+// caseInsensitive is the collation used by the unique channel_name index and lookups against it
+var caseInsensitive = options.Collation{Locale: "en", Strength: 2}
+
+// reservedChannelNames can never be claimed by users; RESERVED_CHANNEL_NAMES adds more
+var reservedChannelNames = map[string]bool{
+	"admin": true, "administrator": true, "api": true, "app": true, "auth": true,
+	"help": true, "login": true, "logout": true, "me": true, "moderator": true,
+	"official": true, "profile": true, "root": true, "search": true, "settings": true,
+	"staff": true, "support": true, "system": true, "u": true, "www": true,
+}
+
+// ChannelNameHold keeps a released channel name reserved for its previous owner
+type ChannelNameHold struct {
+	ChannelName string             `bson:"channel_name" json:"channel_name"`
+	ProfileID   primitive.ObjectID `bson:"profile_id" json:"profile_id"`
+	ReleasedAt  time.Time          `bson:"released_at" json:"released_at"`
+	HeldUntil   time.Time          `bson:"held_until" json:"held_until"`
+}
+
+// EnsureProfileIndexes creates the indexes the profile handlers rely on. Channel name uniqueness
+// is only enforced by its index, so failing to build it is returned rather than logged; existing
+// duplicates have to be cleaned up with DedupeChannelNames first.
+func EnsureProfileIndexes(client *mongo.Client) error {
+	ctx := context.Background()
+	_, err := profilesCollection(client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "channel_name", Value: 1}},
+		Options: options.Index().SetName("channel_name_unique").SetUnique(true).SetCollation(&caseInsensitive),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create unique channel_name index: %v", err)
+	}
+
+	_, err = profilesCollection(client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "name", Value: "text"},
+			{Key: "channel_name", Value: "text"},
+			{Key: "bio", Value: "text"},
+			{Key: "area_of_expert", Value: "text"},
+		},
+		Options: options.Index().SetName("profile_search").SetWeights(bson.M{
+			"channel_name":   10,
+			"name":           8,
+			"area_of_expert": 4,
+			"bio":            1,
+		}),
+	})
+	if err != nil {
+		log.Printf("Error creating profile search index: %v", err)
+	}
+
+	_, err = client.Database("authdb").Collection("channel_name_holds").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "channel_name", Value: 1}, {Key: "held_until", Value: -1}},
+		Options: options.Index().SetName("channel_name_held_until"),
+	})
+	if err != nil {
+		log.Printf("Error creating channel_name_holds index: %v", err)
+	}
+	// Expired holds are only history; Mongo removes them once held_until has passed
+	_, err = client.Database("authdb").Collection("channel_name_holds").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "held_until", Value: 1}},
+		Options: options.Index().SetName("held_until_ttl").SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		log.Printf("Error creating channel_name_holds TTL index: %v", err)
+	}
+	return nil
+}
+
+// DedupeChannelNames gives every profile a channel name that no other profile shares, ignoring
+// case, so the unique index can be built. The oldest profile keeps a contested name; the others,
+// and profiles without a name, get a generated one and are notified. It is safe to run more than once.
+func DedupeChannelNames(client *mongo.Client) error {
+	ctx := context.Background()
+	profiles := profilesCollection(client)
+
+	cursor, err := profiles.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$toLower": bson.M{"$ifNull": bson.A{"$channel_name", ""}}},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"count": bson.M{"$gt": 1}},
+			bson.M{"_id": ""},
+		}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	var groups []struct {
+		Name string               `bson:"_id"`
+		IDs  []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	renamed := 0
+	for _, group := range groups {
+		ids := group.IDs
+		if group.Name != "" {
+			ids = ids[1:]
+		}
+		for _, id := range ids {
+			var user model.User
+			if err := profiles.FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
+				return err
+			}
+			name, err := generateChannelName(ctx, client, user.Name)
+			if err != nil {
+				return fmt.Errorf("failed to generate a channel name for %s: %v", id.Hex(), err)
+			}
+			_, err = profiles.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+				"channel_name":      name,
+				"channel_name_norm": name,
+				"updated_at":        time.Now(),
+			}})
+			if err != nil {
+				return err
+			}
+			log.Printf("Renamed channel %q of %s to %q", user.ChannelName, id.Hex(), name)
+			renamed++
+
+			if user.ChannelName == "" {
+				continue
+			}
+			notification := utils.Notification{
+				UserID:   user.UserID,
+				Email:    user.Email,
+				FCMToken: user.FCMToken,
+				Title:    "Your channel name has changed",
+				Body:     "Another account already used \"" + user.ChannelName + "\", so your channel is now \"" + name + "\". You can pick a new name in your profile settings.",
+			}
+			if err := utils.Notify(ctx, notification); err != nil {
+				log.Printf("Error notifying %s about their new channel name: %v", id.Hex(), err)
+			}
+		}
+	}
+	log.Printf("Renamed %d duplicate or missing channel names", renamed)
+	return nil
+}
+
+func isReservedChannelName(name string) bool {
+	if reservedChannelNames[name] {
+		return true
+	}
+	for _, reserved := range strings.Split(os.Getenv("RESERVED_CHANNEL_NAMES"), ",") {
+		if strings.EqualFold(strings.TrimSpace(reserved), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// channelNameAvailability reports whether name can be claimed by profileID and, if not, why
+func channelNameAvailability(ctx context.Context, client *mongo.Client, name string, profileID primitive.ObjectID) (bool, string, error) {
+	name = strings.ToLower(name)
+	if !channelNamePattern.MatchString(name) {
+		return false, "invalid", nil
+	}
+	if isReservedChannelName(name) {
+		return false, "reserved", nil
+	}
+
+	count, err := profilesCollection(client).CountDocuments(ctx,
+		bson.M{"channel_name": name, "_id": bson.M{"$ne": profileID}},
+		options.Count().SetCollation(&caseInsensitive),
+	)
+	if err != nil {
+		return false, "", err
+	}
+	if count > 0 {
+		return false, "taken", nil
+	}
+
+	// Names released by a rename stay with their previous owner until the hold expires
+	count, err = client.Database("authdb").Collection("channel_name_holds").CountDocuments(ctx, bson.M{
+		"channel_name": name,
+		"profile_id":   bson.M{"$ne": profileID},
+		"held_until":   bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return false, "", err
+	}
+	if count > 0 {
+		return false, "held", nil
+	}
+	return true, "", nil
+}
+
+// generateChannelName derives an available channel name from the display name
+func generateChannelName(ctx context.Context, client *mongo.Client, name string) (string, error) {
+	var base strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '.' {
+			base.WriteRune(r)
+		}
+	}
+	prefix := base.String()
+	if len(prefix) > 20 {
+		prefix = prefix[:20]
+	}
+	if len(prefix) < 3 {
+		prefix = "user" + prefix
+	}
+
+	candidates := make([]string, 0, len(channelNameSuffixDigits)+1)
+	for _, digits := range channelNameSuffixDigits {
+		candidates = append(candidates, fmt.Sprintf("%s%0*d", prefix, digits, rand.Int63n(int64(math.Pow10(digits)))))
+	}
+	// Last resort: the tail of a fresh ObjectID, which is unique per process and time
+	id := primitive.NewObjectID().Hex()
+	candidates = append(candidates, prefix+id[len(id)-10:])
+
+	for _, candidate := range candidates {
+		available, _, err := channelNameAvailability(ctx, client, candidate, primitive.NilObjectID)
+		if err != nil {
+			return "", err
+		}
+		if available {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("could not generate a unique channel name")
+}
+
+// holdChannelName records a released channel name so it cannot be squatted right away
+func holdChannelName(ctx context.Context, client *mongo.Client, name string, profileID primitive.ObjectID) error {
+	now := time.Now()
+	_, err := client.Database("authdb").Collection("channel_name_holds").InsertOne(ctx, ChannelNameHold{
+		ChannelName: strings.ToLower(name),
+		ProfileID:   profileID,
+		ReleasedAt:  now,
+		HeldUntil:   now.Add(channelNameHoldPeriod),
+	})
+	return err
+}
+
+// ChannelNameAvailableHandler handles GET /profile/channel-name/available?name=
+func ChannelNameAvailableHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			writeJSONError(w, "Missing name parameter", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.Background()
+		profileID := primitive.NilObjectID
+		if userID, ok := r.Context().Value("userID").(string); ok {
+			if user, err := findProfileByUserID(ctx, client, userID); err == nil {
+				profileID = user.ID
+			}
+		}
+
+		available, reason, err := channelNameAvailability(ctx, client, name, profileID)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		data := map[string]interface{}{
+			"name":      strings.ToLower(name),
+			"available": available,
+		}
+		if reason != "" {
+			data["reason"] = reason
+		}
+
+		response := Response{
+			Data:    data,
+			Message: "Channel name availability",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// ChannelNameResolver serves /u/{channel_name} routes by resolving the handle to a profile id
+// and delegating to the id based handler. Old handles still on hold redirect to the current one.
+// Both sides look profiles up through profilesCollection, so a resolved id is always found.
+func ChannelNameResolver(client *mongo.Client, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.ToLower(mux.Vars(r)["channel_name"])
+		ctx := context.Background()
+		profiles := profilesCollection(client)
+
+		var user model.User
+		err := profiles.FindOne(ctx,
+			bson.M{"channel_name": name},
+			options.FindOne().SetCollation(&caseInsensitive).SetProjection(bson.M{"_id": 1, "channel_name": 1}),
+		).Decode(&user)
+		if err == nil {
+			query := r.URL.Query()
+			query.Set("id", user.ID.Hex())
+			r.URL.RawQuery = query.Encode()
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err != mongo.ErrNoDocuments {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		var hold ChannelNameHold
+		err = client.Database("authdb").Collection("channel_name_holds").FindOne(ctx,
+			bson.M{"channel_name": name, "held_until": bson.M{"$gt": time.Now()}},
+			options.FindOne().SetSort(bson.M{"released_at": -1}),
+		).Decode(&hold)
+		if err == nil {
+			// A banned profile must not leak its current handle through the redirect
+			err = profiles.FindOne(ctx, publicProfileFilter(hold.ProfileID), options.FindOne().SetProjection(bson.M{"channel_name": 1})).Decode(&user)
+		}
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		target := "/u/" + url.PathEscape(user.ChannelName) + strings.TrimPrefix(r.URL.Path, "/u/"+mux.Vars(r)["channel_name"])
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		// Temporary, since the old handle can be claimed by someone else once the hold expires
+		http.Redirect(w, r, target, http.StatusFound)
+	}
+}
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	model "Backend-Auth-Profiles/models"
 )
 
 type Response struct {
@@ -14,19 +19,20 @@ type Response struct {
 }
 
 type User struct {
-	ID             primitive.ObjectID `bson:"_id"`
-	Email          string             `bson:"email"`
-	Name           string             `bson:"name"`
-	Bio            string             `bson:"bio"`
-	WebAddress     string             `bson:"web_address"`
-	ChannelName    string             `bson:"channel_name"`
-	AreaOfExpert   []string           `bson:"area_of_expert"`
-	ProfilePicture string             `bson:"profile_picture"`
-	Verified       bool               `bson:"verified"`
-	Location       string             `bson:"location"`
-	Provider       string             `bson:"provider"`
-	Followers      []string           `bson:"follower"`
-	Following      []string           `bson:"following"`
+	ID             primitive.ObjectID    `bson:"_id"`
+	Email          string                `bson:"email"`
+	Name           string                `bson:"name"`
+	Bio            string                `bson:"bio"`
+	WebAddress     string                `bson:"web_address"`
+	ChannelName    string                `bson:"channel_name"`
+	AreaOfExpert   []string              `bson:"area_of_expert"`
+	ProfilePicture string                `bson:"profile_picture"`
+	Verified       bool                  `bson:"verified"`
+	Location       string                `bson:"location"`
+	Provider       string                `bson:"provider"`
+	FollowerCount  int64                 `bson:"follower_count"`
+	FollowingCount int64                 `bson:"following_count"`
+	Privacy        model.PrivacySettings `bson:"privacy"`
 }
 
 func writeJSONResponse(w http.ResponseWriter, response Response, statusCode int) {
@@ -43,4 +49,21 @@ func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
 		Status:  false,
 	}
 	writeJSONResponse(w, response, statusCode)
-}
\ No newline at end of file
+}
+
+// profilesCollection is the single home of profiles; login, moderation and every profile write use it
+func profilesCollection(client *mongo.Client) *mongo.Collection {
+	return client.Database("authdb").Collection("profile")
+}
+
+// publicProfileFilter matches a profile the public may see; banned profiles look as if they did not exist
+func publicProfileFilter(id primitive.ObjectID) bson.M {
+	return bson.M{"_id": id, "suspension.type": bson.M{"$ne": "banned"}}
+}
+
+// findProfileByUserID loads the profile of the authenticated caller; tokens carry the provider user_id
+func findProfileByUserID(ctx context.Context, client *mongo.Client, userID string) (model.User, error) {
+	var user model.User
+	err := profilesCollection(client).FindOne(ctx, bson.M{"user_id": userID}).Decode(&user)
+	return user, err
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	model "Backend-Auth-Profiles/models"
+	"Backend-Auth-Profiles/utils"
+)
+
+// completenessNudgeCooldown keeps the reminder from repeating more than once a week
+const completenessNudgeCooldown = 7 * 24 * time.Hour
+
+// completenessItems lists what a complete profile has; the order is used to break weight ties
+var completenessItems = []string{"picture", "bio", "expertise", "interests", "location", "web_address"}
+
+var defaultCompletenessWeights = map[string]int{
+	"picture":     25,
+	"bio":         20,
+	"expertise":   20,
+	"interests":   15,
+	"location":    10,
+	"web_address": 10,
+}
+
+var (
+	completenessWeightsOnce sync.Once
+	completenessWeightsMap  map[string]int
+)
+
+// completenessWeights returns the item weights; PROFILE_COMPLETENESS_WEIGHTS overrides them as "bio=30,picture=20"
+func completenessWeights() map[string]int {
+	completenessWeightsOnce.Do(func() {
+		completenessWeightsMap = loadCompletenessWeights()
+	})
+	return completenessWeightsMap
+}
+
+func loadCompletenessWeights() map[string]int {
+	weights := map[string]int{}
+	for item, weight := range defaultCompletenessWeights {
+		weights[item] = weight
+	}
+	for _, pair := range strings.Split(os.Getenv("PROFILE_COMPLETENESS_WEIGHTS"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		item, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if _, known := weights[item]; !known || err != nil || weight < 0 {
+			log.Printf("Ignoring invalid completeness weight %q", pair)
+			continue
+		}
+		weights[item] = weight
+	}
+	return weights
+}
+
+// profileCompleteness scores a profile from 0 to 100 and lists the missing items, most valuable first
+func profileCompleteness(user model.User) (int, []string) {
+	present := map[string]bool{
+		"picture":     user.ProfilePicture != "",
+		"bio":         strings.TrimSpace(user.Bio) != "",
+		"expertise":   len(user.AreaOfExpert) > 0,
+		"interests":   len(user.AreaOfInterest) > 0,
+		"location":    strings.TrimSpace(user.Location) != "",
+		"web_address": strings.TrimSpace(user.WebAddress) != "",
+	}
+
+	weights := completenessWeights()
+	total, earned := 0, 0
+	missing := []string{}
+	for _, item := range completenessItems {
+		total += weights[item]
+		if present[item] {
+			earned += weights[item]
+		} else {
+			missing = append(missing, item)
+		}
+	}
+	sort.SliceStable(missing, func(i, j int) bool {
+		return weights[missing[i]] > weights[missing[j]]
+	})
+
+	if total == 0 {
+		return 100, missing
+	}
+	return earned * 100 / total, missing
+}
+
+// StartCompletenessNudgeWorker reminds creators with upcoming rooms to finish their profile
+func StartCompletenessNudgeWorker(client *mongo.Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			// Only one instance sends nudges per tick; the lease ends just before the next one
+			acquired, err := acquireJobLease(context.Background(), client, "completeness-nudges", interval*9/10)
+			if err != nil {
+				log.Printf("Error acquiring completeness nudge lease: %v", err)
+			} else if acquired {
+				sendCompletenessNudges(client)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+func sendCompletenessNudges(client *mongo.Client) {
+	ctx := context.Background()
+
+	// Rooms are scheduled in milliseconds since the epoch
+	creators, err := client.Database("myspace").Collection("rooms").Distinct(ctx, "creator._id",
+		bson.M{"schedule": bson.M{"$gt": time.Now().UnixMilli()}})
+	if err != nil {
+		log.Printf("Error finding creators with scheduled rooms: %v", err)
+		return
+	}
+	if len(creators) == 0 {
+		return
+	}
+
+	profiles := profilesCollection(client)
+	cursor, err := profiles.Find(ctx, bson.M{
+		"_id":             bson.M{"$in": creators},
+		"suspension.type": bson.M{"$ne": "banned"},
+		"$or": bson.A{
+			bson.M{"completeness_nudged_at": bson.M{"$exists": false}},
+			bson.M{"completeness_nudged_at": bson.M{"$lt": time.Now().Add(-completenessNudgeCooldown)}},
+		},
+	})
+	if err != nil {
+		log.Printf("Error loading creators for completeness nudges: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	nudged := 0
+	for cursor.Next(ctx) {
+		var user model.User
+		if err := cursor.Decode(&user); err != nil {
+			log.Printf("Error decoding creator for completeness nudge: %v", err)
+			continue
+		}
+		score, missing := profileCompleteness(user)
+		if len(missing) == 0 {
+			continue
+		}
+
+		notification := utils.Notification{
+			UserID:   user.UserID,
+			Email:    user.Email,
+			FCMToken: user.FCMToken,
+			Title:    "Your profile is " + strconv.Itoa(score) + "% complete",
+			Body:     "You have rooms coming up. Add your " + strings.ReplaceAll(strings.Join(missing, ", "), "_", " ") + " so viewers know who they are joining.",
+			Link:     "/profile",
+		}
+		if err := utils.Notify(ctx, notification); err != nil {
+			log.Printf("Error sending completeness nudge to %s: %v", user.ID.Hex(), err)
+			continue
+		}
+		if _, err := profiles.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"completeness_nudged_at": time.Now()}}); err != nil {
+			log.Printf("Error recording completeness nudge for %s: %v", user.ID.Hex(), err)
+		}
+		nudged++
+	}
+	log.Printf("Sent %d profile completeness nudges", nudged)
+}
@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+)
+
+const consentCacheTTL = time.Minute
+
+// LegalDocument is a published version of the terms of service or privacy policy
+type LegalDocument struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Type        string             `bson:"type" json:"type"` // terms or privacy
+	Version     string             `bson:"version" json:"version"`
+	URL         string             `bson:"url" json:"url"`
+	PublishedAt time.Time          `bson:"published_at" json:"published_at"`
+}
+
+// PublishLegalDocumentRequest defines the request structure for publishing a new document version
+type PublishLegalDocumentRequest struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	URL     string `json:"url"`
+}
+
+// AcceptConsentRequest defines the request structure for accepting legal documents
+type AcceptConsentRequest struct {
+	TermsVersion   string `json:"terms_version"`
+	PrivacyVersion string `json:"privacy_version"`
+	// Marketing opt-ins are only changed when sent, so re-accepting new terms keeps them
+	MarketingEmail *bool `json:"marketing_email,omitempty"`
+	MarketingPush  *bool `json:"marketing_push,omitempty"`
+}
+
+type cachedConsent struct {
+	consent   model.Consent
+	fetchedAt time.Time
+}
+
+// MongoConsentChecker implements utils.ConsentChecker against the legal_documents collection
+type MongoConsentChecker struct {
+	client         *mongo.Client
+	mu             sync.Mutex
+	current        map[string]LegalDocument
+	currentFetched time.Time
+	users          map[string]cachedConsent
+}
+
+func NewMongoConsentChecker(client *mongo.Client) *MongoConsentChecker {
+	return &MongoConsentChecker{
+		client: client,
+		users:  map[string]cachedConsent{},
+	}
+}
+
+// Current returns the latest published document of each type, cached briefly
+func (c *MongoConsentChecker) Current() (map[string]LegalDocument, error) {
+	c.mu.Lock()
+	if c.current != nil && time.Since(c.currentFetched) < consentCacheTTL {
+		current := c.current
+		c.mu.Unlock()
+		return current, nil
+	}
+	c.mu.Unlock()
+
+	current, err := currentLegalDocuments(context.Background(), c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.current = current
+	c.currentFetched = time.Now()
+	c.mu.Unlock()
+	return current, nil
+}
+
+// currentLegalDocuments loads the latest published document of each type
+func currentLegalDocuments(ctx context.Context, client *mongo.Client) (map[string]LegalDocument, error) {
+	collection := client.Database("authdb").Collection("legal_documents")
+	current := map[string]LegalDocument{}
+	for _, docType := range []string{"terms", "privacy"} {
+		var doc LegalDocument
+		err := collection.FindOne(
+			ctx,
+			bson.M{"type": docType},
+			options.FindOne().SetSort(bson.M{"published_at": -1}),
+		).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		current[docType] = doc
+	}
+	return current, nil
+}
+
+func (c *MongoConsentChecker) ConsentRequired(userID string) (bool, error) {
+	current, err := c.Current()
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.users[userID]
+	c.mu.Unlock()
+	if !ok || time.Since(cached.fetchedAt) >= consentCacheTTL {
+		user, err := findProfileByUserID(context.Background(), c.client, userID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return false, err
+		}
+		cached = cachedConsent{consent: user.Consent, fetchedAt: time.Now()}
+		c.mu.Lock()
+		c.users[userID] = cached
+		c.mu.Unlock()
+	}
+
+	return !consentUpToDate(cached.consent, current), nil
+}
+
+// Invalidate drops cached state after the user accepts or a new version is published
+func (c *MongoConsentChecker) Invalidate(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if userID == "" {
+		c.current = nil
+		c.users = map[string]cachedConsent{}
+		return
+	}
+	delete(c.users, userID)
+}
+
+// consentUpToDate reports whether the accepted versions match every published document
+func consentUpToDate(consent model.Consent, current map[string]LegalDocument) bool {
+	if terms, ok := current["terms"]; ok && consent.TermsVersion != terms.Version {
+		return false
+	}
+	if privacy, ok := current["privacy"]; ok && consent.PrivacyVersion != privacy.Version {
+		return false
+	}
+	return true
+}
+
+// newUserConsent records consent given at signup when it matches the published versions
+func newUserConsent(ctx context.Context, client *mongo.Client, req SocialAuthRequest) model.Consent {
+	consent := model.Consent{
+		MarketingEmail: req.MarketingEmail,
+		MarketingPush:  req.MarketingPush,
+	}
+	current, err := currentLegalDocuments(ctx, client)
+	if err != nil {
+		return consent
+	}
+
+	now := time.Now()
+	if terms, ok := current["terms"]; ok && req.TermsVersion == terms.Version {
+		consent.TermsVersion = terms.Version
+		consent.TermsAcceptedAt = &now
+	}
+	if privacy, ok := current["privacy"]; ok && req.PrivacyVersion == privacy.Version {
+		consent.PrivacyVersion = privacy.Version
+		consent.PrivacyAcceptedAt = &now
+	}
+	return consent
+}
+
+// CurrentLegalDocumentsHandler handles GET /legal/current
+func CurrentLegalDocumentsHandler(checker *MongoConsentChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		current, err := checker.Current()
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		response := Response{
+			Data:    current,
+			Message: "Current legal documents",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// PublishLegalDocumentHandler handles POST /admin/legal
+func PublishLegalDocumentHandler(client *mongo.Client, checker *MongoConsentChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req PublishLegalDocumentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Type != "terms" && req.Type != "privacy" {
+			writeJSONError(w, "type must be terms or privacy", http.StatusBadRequest)
+			return
+		}
+		if req.Version == "" || req.URL == "" {
+			writeJSONError(w, "version and url are required", http.StatusBadRequest)
+			return
+		}
+
+		collection := client.Database("authdb").Collection("legal_documents")
+		count, err := collection.CountDocuments(context.Background(), bson.M{"type": req.Type, "version": req.Version})
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if count > 0 {
+			writeJSONError(w, "Version already published", http.StatusConflict)
+			return
+		}
+
+		doc := LegalDocument{
+			Type:        req.Type,
+			Version:     req.Version,
+			URL:         req.URL,
+			PublishedAt: time.Now(),
+		}
+		res, err := collection.InsertOne(context.Background(), doc)
+		if err != nil {
+			writeJSONError(w, "Failed to publish document", http.StatusInternalServerError)
+			return
+		}
+		doc.ID = res.InsertedID.(primitive.ObjectID)
+		checker.Invalidate("")
+
+		response := Response{
+			Data:    doc,
+			Message: "Document published",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusCreated)
+	}
+}
+
+// AcceptConsentHandler handles POST /profile/consent
+func AcceptConsentHandler(client *mongo.Client, checker *MongoConsentChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req AcceptConsentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		current, err := checker.Current()
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now()
+		set := bson.M{"updated_at": now}
+		if req.MarketingEmail != nil {
+			set["consent.marketing_email"] = *req.MarketingEmail
+		}
+		if req.MarketingPush != nil {
+			set["consent.marketing_push"] = *req.MarketingPush
+		}
+		if terms, ok := current["terms"]; ok {
+			if req.TermsVersion != terms.Version {
+				writeJSONError(w, "terms_version must be "+terms.Version, http.StatusBadRequest)
+				return
+			}
+			set["consent.terms_version"] = terms.Version
+			set["consent.terms_accepted_at"] = now
+		}
+		if privacy, ok := current["privacy"]; ok {
+			if req.PrivacyVersion != privacy.Version {
+				writeJSONError(w, "privacy_version must be "+privacy.Version, http.StatusBadRequest)
+				return
+			}
+			set["consent.privacy_version"] = privacy.Version
+			set["consent.privacy_accepted_at"] = now
+		}
+
+		var user model.User
+		collection := profilesCollection(client)
+		err = collection.FindOneAndUpdate(
+			context.Background(),
+			bson.M{"user_id": userID},
+			bson.M{"$set": set},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&user)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		checker.Invalidate(userID)
+
+		response := Response{
+			Data:    user.Consent,
+			Message: "Consent recorded; refresh your tokens to clear consent_required",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
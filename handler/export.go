@@ -0,0 +1,350 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	exportLinkTTL = 15 * time.Minute
+	// exportJobTimeout bounds how long a job may stay pending or running, e.g. after a restart
+	exportJobTimeout = time.Hour
+	// exportUpdateAttempts is how often a job's final status is written before it is left to the timeout
+	exportUpdateAttempts = 3
+	// exportFailedMessage is what users see; the underlying error is only logged
+	exportFailedMessage = "The export could not be completed, please request a new one"
+)
+
+// ExportJob tracks an asynchronous personal data export
+type ExportJob struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      string             `bson:"user_id" json:"user_id"`
+	Status      string             `bson:"status" json:"status"`      // pending, running, completed, failed
+	Active      bool               `bson:"active,omitempty" json:"-"` // set while pending or running; unique per user
+	S3Key       string             `bson:"s3_key,omitempty" json:"-"`
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	CompletedAt *time.Time         `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+func exportsCollection(client *mongo.Client) *mongo.Collection {
+	return client.Database("authdb").Collection("exports")
+}
+
+// EnsureExportIndexes creates the index that allows a single active export per user
+func EnsureExportIndexes(client *mongo.Client) {
+	_, err := exportsCollection(client).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().SetName("user_id_active_unique").SetUnique(true).
+			SetPartialFilterExpression(bson.M{"active": true}),
+	})
+	if err != nil {
+		log.Printf("Error creating export indexes: %v", err)
+	}
+}
+
+// failStaleExports fails the user's jobs that have been pending or running for longer than
+// exportJobTimeout; their worker is gone and would otherwise block new exports forever
+func failStaleExports(ctx context.Context, client *mongo.Client, userID string) error {
+	_, err := exportsCollection(client).UpdateMany(ctx,
+		bson.M{
+			"user_id":    userID,
+			"status":     bson.M{"$in": []string{"pending", "running"}},
+			"created_at": bson.M{"$lt": time.Now().Add(-exportJobTimeout)},
+		},
+		bson.M{
+			"$set":   bson.M{"status": "failed", "error": "export timed out"},
+			"$unset": bson.M{"active": ""},
+		},
+	)
+	return err
+}
+
+// RequestDataExportHandler handles POST /profile/export by queueing an export job
+func RequestDataExportHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.Background()
+		collection := exportsCollection(client)
+		if err := failStaleExports(ctx, client, userID); err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		// Only one export may run at a time per user; the partial unique index on active enforces it
+		job := ExportJob{
+			UserID:    userID,
+			Status:    "pending",
+			Active:    true,
+			CreatedAt: time.Now(),
+		}
+		res, err := collection.InsertOne(ctx, job)
+		if mongo.IsDuplicateKeyError(err) {
+			var existing ExportJob
+			if err := collection.FindOne(ctx, bson.M{"user_id": userID, "active": true}).Decode(&existing); err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			response := Response{
+				Data:    existing,
+				Message: "Export already in progress",
+				Status:  true,
+			}
+			writeJSONResponse(w, response, http.StatusAccepted)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Failed to create export", http.StatusInternalServerError)
+			return
+		}
+		job.ID = res.InsertedID.(primitive.ObjectID)
+
+		go runDataExport(client, job)
+
+		response := Response{
+			Data:    job,
+			Message: "Export started",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusAccepted)
+	}
+}
+
+// DataExportStatusHandler handles GET /profile/export/{id}
+func DataExportStatusHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		objID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
+			return
+		}
+
+		collection := exportsCollection(client)
+		var job ExportJob
+		err = collection.FindOne(context.Background(), bson.M{"_id": objID, "user_id": userID}).Decode(&job)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "Export not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		data := map[string]interface{}{
+			"id":         job.ID.Hex(),
+			"status":     job.Status,
+			"created_at": job.CreatedAt,
+		}
+		if job.Error != "" {
+			data["error"] = job.Error
+		}
+		if job.Status == "completed" {
+			url, err := presignExport(job.S3Key)
+			if err != nil {
+				writeJSONError(w, "Failed to sign download link", http.StatusInternalServerError)
+				return
+			}
+			data["completed_at"] = job.CompletedAt
+			data["download_url"] = url
+			data["download_expires_at"] = time.Now().Add(exportLinkTTL)
+		}
+
+		response := Response{
+			Data:    data,
+			Message: "Export status",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// runDataExport collects the user's data, uploads the archive and records the outcome
+func runDataExport(client *mongo.Client, job ExportJob) {
+	ctx := context.Background()
+	collection := exportsCollection(client)
+	if _, err := collection.UpdateOne(ctx, bson.M{"_id": job.ID}, bson.M{"$set": bson.M{"status": "running"}}); err != nil {
+		// Only the status shown to the user is stale; the export itself can still run
+		log.Printf("Error marking export %s running: %v", job.ID.Hex(), err)
+	}
+
+	key, err := buildDataExport(ctx, client, job)
+	if err != nil {
+		log.Printf("Error exporting data for %s: %v", job.UserID, err)
+		finishDataExport(ctx, collection, bson.M{"_id": job.ID}, bson.M{
+			"$set":   bson.M{"status": "failed", "error": exportFailedMessage},
+			"$unset": bson.M{"active": ""},
+		})
+		return
+	}
+
+	// A job that was already failed as stale keeps its status
+	finishDataExport(ctx, collection, bson.M{"_id": job.ID, "active": true}, bson.M{
+		"$set": bson.M{
+			"status":       "completed",
+			"s3_key":       key,
+			"completed_at": time.Now(),
+		},
+		"$unset": bson.M{"active": ""},
+	})
+}
+
+// finishDataExport records the final status of a job, retrying so a transient error does not
+// keep the job active, and the user blocked from a new export, until exportJobTimeout
+func finishDataExport(ctx context.Context, collection *mongo.Collection, filter, update bson.M) {
+	var err error
+	for attempt := 1; attempt <= exportUpdateAttempts; attempt++ {
+		var result *mongo.UpdateResult
+		result, err = collection.UpdateOne(ctx, filter, update)
+		if err == nil {
+			if result.MatchedCount == 0 {
+				log.Printf("Export %v was already closed as stale", filter["_id"])
+			}
+			return
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+	log.Printf("Error recording outcome of export %v, it will time out: %v", filter["_id"], err)
+}
+
+func buildDataExport(ctx context.Context, client *mongo.Client, job ExportJob) (string, error) {
+	user, err := findProfileByUserID(ctx, client, job.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load profile: %v", err)
+	}
+	// The stored document rather than model.User, whose JSON hides fields such as last_login_ip
+	var profile bson.M
+	if err := profilesCollection(client).FindOne(ctx, bson.M{"_id": user.ID}).Decode(&profile); err != nil {
+		return "", fmt.Errorf("failed to load profile: %v", err)
+	}
+
+	activity, err := findAll(ctx, client.Database("authdb").Collection("auth_activity"), bson.M{"user_id": user.UserID})
+	if err != nil {
+		return "", fmt.Errorf("failed to load auth activity: %v", err)
+	}
+	rooms, err := findAll(ctx, client.Database("myspace").Collection("rooms"), bson.M{"creator._id": user.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to load rooms: %v", err)
+	}
+	videos, err := findAll(ctx, client.Database("videos").Collection("upload"), bson.M{"profile._id": user.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to load videos: %v", err)
+	}
+
+	followers, err := findAll(ctx, followsCollection(client), bson.M{"followee_id": user.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to load followers: %v", err)
+	}
+	following, err := findAll(ctx, followsCollection(client), bson.M{"follower_id": user.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to load following: %v", err)
+	}
+
+	files := map[string]interface{}{
+		"profile.json":       profile,
+		"devices.json":       map[string]interface{}{"device_id_list": user.DeviceIDList, "fcm_token": user.FCMToken},
+		"auth_activity.json": activity,
+		"followers.json":     followers,
+		"following.json":     following,
+		"rooms.json":         rooms,
+		"videos.json":        videos,
+	}
+
+	archive, err := zipJSONFiles(files)
+	if err != nil {
+		return "", err
+	}
+
+	awsSession, err := newAWSSession()
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize AWS session: %v", err)
+	}
+	key := fmt.Sprintf("exports/%s/%s.zip", user.ID.Hex(), job.ID.Hex())
+	_, err = s3manager.NewUploader(awsSession).Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(os.Getenv("AWS_BUCKET")),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(archive),
+		ACL:         aws.String("private"),
+		ContentType: aws.String("application/zip"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload export: %v", err)
+	}
+	return key, nil
+}
+
+// findAll returns every document matching filter, never nil so it encodes as []
+func findAll(ctx context.Context, collection *mongo.Collection, filter bson.M) ([]bson.M, error) {
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	docs := []bson.M{}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// zipJSONFiles encodes each value as an indented JSON file inside a ZIP archive
+func zipJSONFiles(files map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, value := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %v", name, err)
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(value); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize archive: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// presignExport returns a short-lived download URL for a private export archive
+func presignExport(key string) (string, error) {
+	awsSession, err := newAWSSession()
+	if err != nil {
+		return "", err
+	}
+	req, _ := s3.New(awsSession).GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(os.Getenv("AWS_BUCKET")),
+		Key:    aws.String(key),
+	})
+	return req.Presign(exportLinkTTL)
+}
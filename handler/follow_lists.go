@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultFollowListLimit = 20
+	maxFollowListLimit     = 100
+)
+
+// miniProfileFields is the projection behind ProfileSuggestion
+var miniProfileFields = bson.M{"_id": 1, "channel_name": 1, "name": 1, "profile_picture": 1, "verified": 1}
+
+// followListCursor marks the last edge of a page; the next page starts strictly after it
+type followListCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// FollowListEntry is one row of a followers or following list
+type FollowListEntry struct {
+	ProfileSuggestion
+	FollowedAt  time.Time `json:"followed_at"`
+	IsFollowing *bool     `json:"is_following,omitempty"` // Only set for authenticated callers
+}
+
+func encodeFollowListCursor(c followListCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeFollowListCursor(s string) (followListCursor, primitive.ObjectID, error) {
+	var c followListCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, primitive.NilObjectID, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, primitive.NilObjectID, err
+	}
+	id, err := primitive.ObjectIDFromHex(c.ID)
+	return c, id, err
+}
+
+// miniProfiles loads the compact profiles for ids, leaving out banned accounts
+func miniProfiles(ctx context.Context, client *mongo.Client, ids []primitive.ObjectID) (map[primitive.ObjectID]ProfileSuggestion, error) {
+	profiles := map[primitive.ObjectID]ProfileSuggestion{}
+	if len(ids) == 0 {
+		return profiles, nil
+	}
+	cursor, err := profilesCollection(client).Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "suspension.type": bson.M{"$ne": "banned"}},
+		options.Find().SetProjection(miniProfileFields),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		profiles[user.ID] = ProfileSuggestion{
+			ID:             user.ID.Hex(),
+			ChannelName:    user.ChannelName,
+			Name:           user.Name,
+			ProfilePicture: user.ProfilePicture,
+			Verified:       user.Verified,
+		}
+	}
+	return profiles, nil
+}
+
+// FollowersHandler handles GET /profile/{id}/followers
+func FollowersHandler(client *mongo.Client) http.HandlerFunc {
+	return followListHandler(client, "followee_id", "follower_id")
+}
+
+// FollowingHandler handles GET /profile/{id}/following
+func FollowingHandler(client *mongo.Client) http.HandlerFunc {
+	return followListHandler(client, "follower_id", "followee_id")
+}
+
+// followListHandler lists the edges whose ownField is the profile in the path, newest first,
+// and renders the profile on the otherField side of each edge
+func followListHandler(client *mongo.Client, ownField, otherField string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		profileID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
+			return
+		}
+		limit := parseLimit(r, defaultFollowListLimit, maxFollowListLimit)
+
+		ctx := context.Background()
+		target, err := profileVisibility(ctx, client, profileID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		hidden, err := hiddenByBlock(ctx, r, client, profileID)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if hidden {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if !canSee(effectivePrivacy(target).FollowLists, viewerAccess(ctx, r, client, profileID)) {
+			writeJSONError(w, "Follow lists are not visible to you", http.StatusForbidden)
+			return
+		}
+
+		filter := bson.M{ownField: profileID}
+		if cursorParam := r.URL.Query().Get("cursor"); cursorParam != "" {
+			cursor, lastID, err := decodeFollowListCursor(cursorParam)
+			if err != nil {
+				writeJSONError(w, "Invalid cursor", http.StatusBadRequest)
+				return
+			}
+			filter["$or"] = bson.A{
+				bson.M{"created_at": bson.M{"$lt": cursor.CreatedAt}},
+				bson.M{"created_at": cursor.CreatedAt, "_id": bson.M{"$lt": lastID}},
+			}
+		}
+
+		cur, err := followsCollection(client).Find(ctx, filter, options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+			SetLimit(int64(limit+1)))
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer cur.Close(ctx)
+
+		var edges []Follow
+		if err := cur.All(ctx, &edges); err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		var nextCursor string
+		if len(edges) > limit {
+			edges = edges[:limit]
+			last := edges[limit-1]
+			nextCursor = encodeFollowListCursor(followListCursor{CreatedAt: last.CreatedAt, ID: last.ID.Hex()})
+		}
+
+		otherID := func(edge Follow) primitive.ObjectID {
+			if otherField == "follower_id" {
+				return edge.FollowerID
+			}
+			return edge.FolloweeID
+		}
+		ids := make([]primitive.ObjectID, 0, len(edges))
+		for _, edge := range edges {
+			ids = append(ids, otherID(edge))
+		}
+
+		profiles, err := miniProfiles(ctx, client, ids)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		// is_following is resolved for the whole page with one query
+		var followed map[primitive.ObjectID]bool
+		if userID, ok := r.Context().Value("userID").(string); ok {
+			if caller, err := findProfileByUserID(ctx, client, userID); err == nil {
+				followed, err = followedAmong(ctx, client, caller.ID, ids)
+				if err != nil {
+					writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+
+		entries := []FollowListEntry{}
+		for _, edge := range edges {
+			profile, ok := profiles[otherID(edge)]
+			if !ok {
+				continue
+			}
+			entry := FollowListEntry{ProfileSuggestion: profile, FollowedAt: edge.CreatedAt}
+			if followed != nil {
+				isFollowingEntry := followed[otherID(edge)]
+				entry.IsFollowing = &isFollowingEntry
+			}
+			entries = append(entries, entry)
+		}
+
+		response := Response{
+			Data: map[string]interface{}{
+				"profiles":    entries,
+				"next_cursor": nextCursor,
+			},
+			Message: "Follow list",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
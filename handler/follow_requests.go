@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+	"Backend-Auth-Profiles/utils"
+)
+
+// followRequestsCollection holds pending follows of private accounts; documents have the shape of Follow
+func followRequestsCollection(client *mongo.Client) *mongo.Collection {
+	return client.Database("authdb").Collection("follow_requests")
+}
+
+// EnsureFollowRequestIndexes creates the unique request index and the index behind the incoming list
+func EnsureFollowRequestIndexes(client *mongo.Client) {
+	_, err := followRequestsCollection(client).Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+			Options: options.Index().SetName("follower_followee_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "followee_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("followee_created_at"),
+		},
+	})
+	if err != nil {
+		log.Printf("Error creating follow request indexes: %v", err)
+	}
+}
+
+// requestFollow records a pending follow and lets the private account know about it
+func requestFollow(ctx context.Context, client *mongo.Client, caller model.User, targetID primitive.ObjectID) error {
+	_, err := followRequestsCollection(client).InsertOne(ctx, Follow{
+		FollowerID: caller.ID,
+		FolloweeID: targetID,
+		CreatedAt:  time.Now(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var target model.User
+	if err := profilesCollection(client).FindOne(ctx, bson.M{"_id": targetID}).Decode(&target); err == nil {
+		notification := utils.Notification{
+			UserID:   target.UserID,
+			Email:    target.Email,
+			FCMToken: target.FCMToken,
+			Title:    "New follow request",
+			Body:     caller.ChannelName + " wants to follow you.",
+			ActorID:  caller.ID.Hex(),
+		}
+		go func() {
+			if err := utils.Notify(context.Background(), notification); err != nil {
+				log.Printf("Error sending follow request notification: %v", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// acceptFollowRequest turns a pending request into a follow edge
+func acceptFollowRequest(ctx context.Context, client *mongo.Client, request Follow) error {
+	_, err := followsCollection(client).InsertOne(ctx, Follow{
+		FollowerID: request.FollowerID,
+		FolloweeID: request.FolloweeID,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	if err == nil {
+		if err := adjustFollowCounts(ctx, client, request.FollowerID, request.FolloweeID, 1); err != nil {
+			log.Printf("Error updating follow counts: %v", err)
+		}
+	}
+	_, err = followRequestsCollection(client).DeleteOne(ctx, bson.M{"follower_id": request.FollowerID, "followee_id": request.FolloweeID})
+	return err
+}
+
+// acceptAllFollowRequests approves everything pending for a profile that stopped being private
+func acceptAllFollowRequests(ctx context.Context, client *mongo.Client, profileID primitive.ObjectID) error {
+	cursor, err := followRequestsCollection(client).Find(ctx, bson.M{"followee_id": profileID})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var requests []Follow
+	if err := cursor.All(ctx, &requests); err != nil {
+		return err
+	}
+	for _, request := range requests {
+		if err := acceptFollowRequest(ctx, client, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FollowRequestsHandler handles GET /profile/follow-requests, the caller's incoming requests newest first
+func FollowRequestsHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		limit := parseLimit(r, defaultFollowListLimit, maxFollowListLimit)
+
+		ctx := context.Background()
+		caller, err := findProfileByUserID(ctx, client, userID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		filter := bson.M{"followee_id": caller.ID}
+		if cursorParam := r.URL.Query().Get("cursor"); cursorParam != "" {
+			cursor, lastID, err := decodeFollowListCursor(cursorParam)
+			if err != nil {
+				writeJSONError(w, "Invalid cursor", http.StatusBadRequest)
+				return
+			}
+			filter["$or"] = bson.A{
+				bson.M{"created_at": bson.M{"$lt": cursor.CreatedAt}},
+				bson.M{"created_at": cursor.CreatedAt, "_id": bson.M{"$lt": lastID}},
+			}
+		}
+
+		cur, err := followRequestsCollection(client).Find(ctx, filter, options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+			SetLimit(int64(limit+1)))
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer cur.Close(ctx)
+
+		var requests []Follow
+		if err := cur.All(ctx, &requests); err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		var nextCursor string
+		if len(requests) > limit {
+			requests = requests[:limit]
+			last := requests[limit-1]
+			nextCursor = encodeFollowListCursor(followListCursor{CreatedAt: last.CreatedAt, ID: last.ID.Hex()})
+		}
+
+		ids := make([]primitive.ObjectID, 0, len(requests))
+		for _, request := range requests {
+			ids = append(ids, request.FollowerID)
+		}
+		profiles, err := miniProfiles(ctx, client, ids)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		entries := []map[string]interface{}{}
+		for _, request := range requests {
+			if profile, ok := profiles[request.FollowerID]; ok {
+				entries = append(entries, map[string]interface{}{
+					"profile":      profile,
+					"requested_at": request.CreatedAt,
+				})
+			}
+		}
+
+		response := Response{
+			Data: map[string]interface{}{
+				"requests":    entries,
+				"next_cursor": nextCursor,
+			},
+			Message: "Follow requests",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// ApproveFollowRequestHandler handles POST /profile/follow-requests/{id}/approve, where id is the requester's profile id
+func ApproveFollowRequestHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reviewFollowRequest(w, r, client, true)
+	}
+}
+
+// RejectFollowRequestHandler handles POST /profile/follow-requests/{id}/reject
+func RejectFollowRequestHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reviewFollowRequest(w, r, client, false)
+	}
+}
+
+func reviewFollowRequest(w http.ResponseWriter, r *http.Request, client *mongo.Client, approve bool) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	requesterID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSONError(w, "Invalid id format", http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	caller, err := findProfileByUserID(ctx, client, userID)
+	if err == mongo.ErrNoDocuments {
+		writeJSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var request Follow
+	err = followRequestsCollection(client).FindOne(ctx, bson.M{"follower_id": requesterID, "followee_id": caller.ID}).Decode(&request)
+	if err == mongo.ErrNoDocuments {
+		writeJSONError(w, "Follow request not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if !approve {
+		if _, err := followRequestsCollection(client).DeleteOne(ctx, bson.M{"_id": request.ID}); err != nil {
+			writeJSONError(w, "Failed to reject follow request", http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, Response{Message: "Follow request rejected", Status: true}, http.StatusOK)
+		return
+	}
+
+	if err := acceptFollowRequest(ctx, client, request); err != nil {
+		writeJSONError(w, "Failed to approve follow request", http.StatusInternalServerError)
+		return
+	}
+
+	var requester model.User
+	if err := profilesCollection(client).FindOne(ctx, bson.M{"_id": requesterID}).Decode(&requester); err == nil {
+		notification := utils.Notification{
+			UserID:   requester.UserID,
+			Email:    requester.Email,
+			FCMToken: requester.FCMToken,
+			Title:    "Follow request approved",
+			Body:     caller.ChannelName + " approved your follow request.",
+			ActorID:  caller.ID.Hex(),
+		}
+		go func() {
+			if err := utils.Notify(context.Background(), notification); err != nil {
+				log.Printf("Error sending follow approval notification: %v", err)
+			}
+		}()
+	}
+
+	writeJSONResponse(w, Response{Message: "Follow request approved", Status: true}, http.StatusOK)
+}
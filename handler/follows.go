@@ -0,0 +1,457 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+	"Backend-Auth-Profiles/utils"
+)
+
+// Follow is an edge in the follow graph: FollowerID follows FolloweeID
+type Follow struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FollowerID primitive.ObjectID `bson:"follower_id" json:"follower_id"`
+	FolloweeID primitive.ObjectID `bson:"followee_id" json:"followee_id"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+func followsCollection(client *mongo.Client) *mongo.Collection {
+	return client.Database("authdb").Collection("follows")
+}
+
+// EnsureFollowIndexes creates the unique edge index and the indexes used to list each side
+func EnsureFollowIndexes(client *mongo.Client) {
+	_, err := followsCollection(client).Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+			Options: options.Index().SetName("follower_followee_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "followee_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("followee_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("follower_created_at"),
+		},
+	})
+	if err != nil {
+		log.Printf("Error creating follow indexes: %v", err)
+	}
+}
+
+// isFollowing reports whether followerID follows followeeID
+func isFollowing(ctx context.Context, client *mongo.Client, followerID, followeeID primitive.ObjectID) (bool, error) {
+	count, err := followsCollection(client).CountDocuments(ctx, bson.M{"follower_id": followerID, "followee_id": followeeID}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// followedAmong returns which of ids are followed by followerID, in a single query
+func followedAmong(ctx context.Context, client *mongo.Client, followerID primitive.ObjectID, ids []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	followed := map[primitive.ObjectID]bool{}
+	if len(ids) == 0 {
+		return followed, nil
+	}
+	cursor, err := followsCollection(client).Find(ctx,
+		bson.M{"follower_id": followerID, "followee_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"followee_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var edges []Follow
+	if err := cursor.All(ctx, &edges); err != nil {
+		return nil, err
+	}
+	for _, edge := range edges {
+		followed[edge.FolloweeID] = true
+	}
+	return followed, nil
+}
+
+// adjustFollowCounts keeps the denormalized counters on both profiles in step with the edges.
+// The edge is already written when it runs, so if an increment fails both counters are recomputed
+// from the edges instead; anything still off is fixed by RecountFollowCounts (-recount-follows).
+func adjustFollowCounts(ctx context.Context, client *mongo.Client, followerID, followeeID primitive.ObjectID, delta int) error {
+	profiles := profilesCollection(client)
+	_, err := profiles.UpdateOne(ctx, bson.M{"_id": followerID}, bson.M{"$inc": bson.M{"following_count": delta}})
+	if err == nil {
+		_, err = profiles.UpdateOne(ctx, bson.M{"_id": followeeID}, bson.M{"$inc": bson.M{"follower_count": delta}})
+	}
+	if err == nil {
+		return nil
+	}
+	log.Printf("Error updating follow counts, recounting: %v", err)
+	if err := recountFollows(ctx, client, followerID); err != nil {
+		return err
+	}
+	return recountFollows(ctx, client, followeeID)
+}
+
+// resolveFollowTarget loads the caller and the profile in the {id} path variable for follow, block and mute
+func resolveFollowTarget(w http.ResponseWriter, r *http.Request, client *mongo.Client) (model.User, primitive.ObjectID, bool) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return model.User{}, primitive.NilObjectID, false
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSONError(w, "Invalid id format", http.StatusBadRequest)
+		return model.User{}, primitive.NilObjectID, false
+	}
+
+	caller, err := findProfileByUserID(context.Background(), client, userID)
+	if err == mongo.ErrNoDocuments {
+		writeJSONError(w, "User not found", http.StatusNotFound)
+		return model.User{}, primitive.NilObjectID, false
+	}
+	if err != nil {
+		writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		return model.User{}, primitive.NilObjectID, false
+	}
+	if caller.ID == targetID {
+		writeJSONError(w, "You cannot target your own profile", http.StatusBadRequest)
+		return model.User{}, primitive.NilObjectID, false
+	}
+	return caller, targetID, true
+}
+
+// FollowHandler handles POST /profile/{id}/follow
+func FollowHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, targetID, ok := resolveFollowTarget(w, r, client)
+		if !ok {
+			return
+		}
+
+		ctx := context.Background()
+		var target model.User
+		err := profilesCollection(client).FindOne(ctx,
+			bson.M{"_id": targetID, "suspension.type": bson.M{"$ne": "banned"}},
+			options.FindOne().SetProjection(bson.M{"_id": 1, "private": 1}),
+		).Decode(&target)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		blocked, err := isBlockedBetween(ctx, client, caller.ID, targetID)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if blocked {
+			writeJSONError(w, "You cannot follow this user", http.StatusForbidden)
+			return
+		}
+
+		// Private accounts approve their followers; the follow waits as a request until then
+		if target.Private {
+			following, err := isFollowing(ctx, client, caller.ID, targetID)
+			if err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if !following {
+				if err := requestFollow(ctx, client, caller, targetID); err != nil {
+					writeJSONError(w, "Failed to request follow", http.StatusInternalServerError)
+					return
+				}
+				response := Response{
+					Data:    map[string]interface{}{"is_following": false, "requested": true},
+					Message: "Follow requested",
+					Status:  true,
+				}
+				writeJSONResponse(w, response, http.StatusAccepted)
+				return
+			}
+		}
+
+		_, err = followsCollection(client).InsertOne(ctx, Follow{
+			FollowerID: caller.ID,
+			FolloweeID: targetID,
+			CreatedAt:  time.Now(),
+		})
+		if mongo.IsDuplicateKeyError(err) {
+			writeJSONResponse(w, Response{Message: "Already following", Status: true}, http.StatusOK)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Failed to follow", http.StatusInternalServerError)
+			return
+		}
+		// A block that landed between the check above and the insert did not see this edge, so
+		// look again and take the edge back rather than leave a follow across a block
+		blocked, err = isBlockedBetween(ctx, client, caller.ID, targetID)
+		if err != nil || blocked {
+			if _, delErr := followsCollection(client).DeleteOne(ctx, bson.M{"follower_id": caller.ID, "followee_id": targetID}); delErr != nil {
+				log.Printf("Error removing follow across a block: %v", delErr)
+			}
+			if err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			writeJSONError(w, "You cannot follow this user", http.StatusForbidden)
+			return
+		}
+		if err := adjustFollowCounts(ctx, client, caller.ID, targetID, 1); err != nil {
+			log.Printf("Error updating follow counts: %v", err)
+		}
+		notifyNewFollower(ctx, client, caller, targetID)
+
+		response := Response{
+			Data:    map[string]interface{}{"is_following": true},
+			Message: "Followed",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// notifyNewFollower tells the followed profile about the follow. ActorID lets recipients who
+// muted or blocked the follower skip it.
+func notifyNewFollower(ctx context.Context, client *mongo.Client, follower model.User, followeeID primitive.ObjectID) {
+	var followee model.User
+	if err := profilesCollection(client).FindOne(ctx, bson.M{"_id": followeeID}).Decode(&followee); err != nil {
+		return
+	}
+	notification := utils.Notification{
+		UserID:   followee.UserID,
+		Email:    followee.Email,
+		FCMToken: followee.FCMToken,
+		Title:    "New follower",
+		Body:     follower.ChannelName + " started following you.",
+		ActorID:  follower.ID.Hex(),
+	}
+	go func() {
+		if err := utils.Notify(context.Background(), notification); err != nil {
+			log.Printf("Error sending new follower notification: %v", err)
+		}
+	}()
+}
+
+// UnfollowHandler handles DELETE /profile/{id}/follow
+func UnfollowHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, targetID, ok := resolveFollowTarget(w, r, client)
+		if !ok {
+			return
+		}
+
+		ctx := context.Background()
+		// Unfollowing also withdraws a pending request
+		if _, err := followRequestsCollection(client).DeleteOne(ctx, bson.M{"follower_id": caller.ID, "followee_id": targetID}); err != nil {
+			writeJSONError(w, "Failed to unfollow", http.StatusInternalServerError)
+			return
+		}
+		result, err := followsCollection(client).DeleteOne(ctx, bson.M{"follower_id": caller.ID, "followee_id": targetID})
+		if err != nil {
+			writeJSONError(w, "Failed to unfollow", http.StatusInternalServerError)
+			return
+		}
+		if result.DeletedCount > 0 {
+			if err := adjustFollowCounts(ctx, client, caller.ID, targetID, -1); err != nil {
+				log.Printf("Error updating follow counts: %v", err)
+			}
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"is_following": false},
+			Message: "Unfollowed",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// deleteFollowEdges removes every edge touching profileID and decrements the counters of the other side
+func deleteFollowEdges(ctx context.Context, client *mongo.Client, profileID primitive.ObjectID) error {
+	profiles := profilesCollection(client)
+	edges := followsCollection(client)
+
+	var followees, followers []primitive.ObjectID
+	cursor, err := edges.Find(ctx, bson.M{"$or": bson.A{bson.M{"follower_id": profileID}, bson.M{"followee_id": profileID}}})
+	if err != nil {
+		return err
+	}
+	var all []Follow
+	if err := cursor.All(ctx, &all); err != nil {
+		return err
+	}
+	for _, edge := range all {
+		if edge.FollowerID == profileID {
+			followees = append(followees, edge.FolloweeID)
+		} else {
+			followers = append(followers, edge.FollowerID)
+		}
+	}
+
+	if len(followees) > 0 {
+		if _, err := profiles.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": followees}}, bson.M{"$inc": bson.M{"follower_count": -1}}); err != nil {
+			return err
+		}
+	}
+	if len(followers) > 0 {
+		if _, err := profiles.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": followers}}, bson.M{"$inc": bson.M{"following_count": -1}}); err != nil {
+			return err
+		}
+	}
+	_, err = edges.DeleteMany(ctx, bson.M{"$or": bson.A{bson.M{"follower_id": profileID}, bson.M{"followee_id": profileID}}})
+	return err
+}
+
+// legacyFollowArrays is the part of a profile document that predates the follows collection
+type legacyFollowArrays struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Follower  []string           `bson:"follower"`
+	Following []string           `bson:"following"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// MigrateFollowArrays moves the legacy follower/following arrays into follow edges, recomputes
+// the counters and drops the arrays. Array entries may hold the token user_id or the profile id.
+// It is idempotent and safe to run more than once.
+func MigrateFollowArrays(client *mongo.Client) error {
+	ctx := context.Background()
+	profiles := profilesCollection(client)
+
+	resolve := map[string]primitive.ObjectID{}
+	lookup := func(ref string) (primitive.ObjectID, bool) {
+		if id, ok := resolve[ref]; ok {
+			return id, !id.IsZero()
+		}
+		filter := bson.M{"user_id": ref}
+		if oid, err := primitive.ObjectIDFromHex(ref); err == nil {
+			filter = bson.M{"$or": bson.A{bson.M{"user_id": ref}, bson.M{"_id": oid}}}
+		}
+		var user model.User
+		err := profiles.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&user)
+		if err != nil {
+			resolve[ref] = primitive.NilObjectID
+			return primitive.NilObjectID, false
+		}
+		resolve[ref] = user.ID
+		return user.ID, true
+	}
+
+	cursor, err := profiles.Find(ctx,
+		bson.M{"$or": bson.A{bson.M{"follower": bson.M{"$exists": true}}, bson.M{"following": bson.M{"$exists": true}}}},
+		options.Find().SetProjection(bson.M{"_id": 1, "follower": 1, "following": 1, "created_at": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var legacy legacyFollowArrays
+		if err := cursor.Decode(&legacy); err != nil {
+			return err
+		}
+		ids = append(ids, legacy.ID)
+
+		var edges []mongo.WriteModel
+		addEdge := func(followerID, followeeID primitive.ObjectID) {
+			if followerID == followeeID {
+				return
+			}
+			edges = append(edges, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"follower_id": followerID, "followee_id": followeeID}).
+				SetUpdate(bson.M{"$setOnInsert": bson.M{"created_at": legacy.CreatedAt}}).
+				SetUpsert(true))
+		}
+		for _, ref := range legacy.Follower {
+			if followerID, ok := lookup(ref); ok {
+				addEdge(followerID, legacy.ID)
+			}
+		}
+		for _, ref := range legacy.Following {
+			if followeeID, ok := lookup(ref); ok {
+				addEdge(legacy.ID, followeeID)
+			}
+		}
+		if len(edges) > 0 {
+			if _, err := followsCollection(client).BulkWrite(ctx, edges, options.BulkWrite().SetOrdered(false)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	// Counters are recomputed for everyone since an edge can point at a profile that had no arrays
+	if err := RecountFollowCounts(client); err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		if _, err := profiles.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"$unset": bson.M{"follower": "", "following": ""}}); err != nil {
+			return err
+		}
+	}
+	log.Printf("Migrated follow arrays for %d profiles", len(ids))
+	return nil
+}
+
+// RecountFollowCounts recomputes follower_count and following_count of every profile from the
+// edges. It reconciles counters that drifted when an update failed after its edge was written.
+func RecountFollowCounts(client *mongo.Client) error {
+	ctx := context.Background()
+	cursor, err := profilesCollection(client).Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	recounted := 0
+	for cursor.Next(ctx) {
+		var user model.User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+		if err := recountFollows(ctx, client, user.ID); err != nil {
+			return err
+		}
+		recounted++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	log.Printf("Recounted follows for %d profiles", recounted)
+	return nil
+}
+
+// recountFollows recomputes the follower and following counters of a profile from the edges
+func recountFollows(ctx context.Context, client *mongo.Client, id primitive.ObjectID) error {
+	followers, err := followsCollection(client).CountDocuments(ctx, bson.M{"followee_id": id})
+	if err != nil {
+		return err
+	}
+	following, err := followsCollection(client).CountDocuments(ctx, bson.M{"follower_id": id})
+	if err != nil {
+		return err
+	}
+	_, err = profilesCollection(client).UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"follower_count": followers, "following_count": following}},
+	)
+	return err
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// jobLeasesCollection holds one document per scheduled job, {_id: name, holder, locked_until}.
+// The instance holding a job's lease runs it, so a job runs once per slot however many
+// instances are deployed.
+func jobLeasesCollection(client *mongo.Client) *mongo.Collection {
+	return client.Database("authdb").Collection("job_leases")
+}
+
+// acquireJobLease takes the named lease for ttl unless another holder still has it.
+// An expired lease matches the filter and is taken over; a live one makes the upsert
+// collide with the existing _id, which means someone else is running the job.
+func acquireJobLease(ctx context.Context, client *mongo.Client, name string, ttl time.Duration) (bool, error) {
+	holder, _ := os.Hostname()
+	now := time.Now()
+	_, err := jobLeasesCollection(client).UpdateOne(ctx,
+		bson.M{"_id": name, "locked_until": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"holder": holder, "locked_until": now.Add(ttl)}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+)
+
+const accountStatusCacheTTL = time.Minute
+
+// SuspendUserRequest defines the request structure for suspending or banning a user
+type SuspendUserRequest struct {
+	Type          string `json:"type"`                     // suspended or banned
+	Reason        string `json:"reason"`                   // Required
+	DurationHours int    `json:"duration_hours,omitempty"` // Optional, indefinite when zero
+}
+
+type cachedAccountStatus struct {
+	suspension *model.Suspension
+	fetchedAt  time.Time
+}
+
+// MongoAccountStatusStore implements utils.AccountStatusChecker with a short-lived cache
+// so JWTMiddlewareFor does not hit Mongo on every request
+type MongoAccountStatusStore struct {
+	collection *mongo.Collection
+	mu         sync.Mutex
+	cache      map[string]cachedAccountStatus
+}
+
+func NewMongoAccountStatusStore(client *mongo.Client) *MongoAccountStatusStore {
+	return &MongoAccountStatusStore{
+		collection: profilesCollection(client),
+		cache:      map[string]cachedAccountStatus{},
+	}
+}
+
+func (s *MongoAccountStatusStore) IsBlocked(userID string) (bool, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[userID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < accountStatusCacheTTL {
+		return cached.suspension.Active(), nil
+	}
+
+	var user model.User
+	err := s.collection.FindOne(
+		context.Background(),
+		bson.M{"user_id": userID},
+		options.FindOne().SetProjection(bson.M{"suspension": 1}),
+	).Decode(&user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.cache[userID] = cachedAccountStatus{suspension: user.Suspension, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return user.Suspension.Active(), nil
+}
+
+// Invalidate drops the cached status so the next request sees the change immediately
+func (s *MongoAccountStatusStore) Invalidate(userID string) {
+	s.mu.Lock()
+	delete(s.cache, userID)
+	s.mu.Unlock()
+}
+
+// SuspendUserHandler handles POST /admin/users/{id}/suspension
+func SuspendUserHandler(client *mongo.Client, store *MongoAccountStatusStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		objID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
+			return
+		}
+
+		var req SuspendUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Type != "suspended" && req.Type != "banned" {
+			writeJSONError(w, "type must be suspended or banned", http.StatusBadRequest)
+			return
+		}
+		if req.Reason == "" {
+			writeJSONError(w, "reason is required", http.StatusBadRequest)
+			return
+		}
+
+		suspension := model.Suspension{
+			Type:      req.Type,
+			Reason:    req.Reason,
+			CreatedAt: time.Now(),
+		}
+		if req.DurationHours > 0 {
+			expiresAt := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+			suspension.ExpiresAt = &expiresAt
+		}
+
+		var user model.User
+		collection := profilesCollection(client)
+		err = collection.FindOneAndUpdate(
+			context.Background(),
+			bson.M{"_id": objID},
+			bson.M{"$set": bson.M{"suspension": suspension, "updated_at": time.Now()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&user)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		store.Invalidate(user.UserID)
+
+		response := Response{
+			Data:    suspension,
+			Message: "User " + req.Type,
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// LiftSuspensionHandler handles DELETE /admin/users/{id}/suspension
+func LiftSuspensionHandler(client *mongo.Client, store *MongoAccountStatusStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		objID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
+			return
+		}
+
+		var user model.User
+		collection := profilesCollection(client)
+		err = collection.FindOneAndUpdate(
+			context.Background(),
+			bson.M{"_id": objID},
+			bson.M{"$unset": bson.M{"suspension": ""}, "$set": bson.M{"updated_at": time.Now()}},
+		).Decode(&user)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		store.Invalidate(user.UserID)
+
+		response := Response{
+			Message: "Suspension lifted",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
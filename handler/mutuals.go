@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	model "Backend-Auth-Profiles/models"
+)
+
+const (
+	// mutualSummarySize is how many names the public profile shows before "and N others"
+	mutualSummarySize = 3
+	// mutualScanLimit caps the edges the public profile summary looks at
+	mutualScanLimit = 1000
+)
+
+// MutualSummary is the "followed by people you follow" block of a public profile
+type MutualSummary struct {
+	Count             int64               `json:"count"`
+	CountIsLowerBound bool                `json:"count_is_lower_bound,omitempty"`
+	Profiles          []ProfileSuggestion `json:"profiles"`
+}
+
+// mutualFollowersPipeline matches the caller's following edges whose followee also follows targetID.
+// Each lookup is a point query on the unique follower/followee index.
+func mutualFollowersPipeline(callerID, targetID primitive.ObjectID) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"follower_id": callerID, "followee_id": bson.M{"$ne": targetID}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "follows",
+			"let":  bson.M{"followee": "$followee_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$follower_id", "$$followee"}},
+					bson.M{"$eq": bson.A{"$followee_id", targetID}},
+				}}}},
+				bson.M{"$limit": 1},
+			},
+			"as": "mutual",
+		}}},
+		{{Key: "$match", Value: bson.M{"mutual": bson.M{"$ne": bson.A{}}}}},
+		{{Key: "$project", Value: bson.M{"mutual": 0}}},
+	}
+}
+
+// mutualSummaryPipeline matches the edges that make someone a mutual, starting from whichever
+// side has fewer edges: the caller's following or the target's followers. Only the most recent
+// mutualScanLimit edges of that side are looked at, so the cost of a profile view is bounded.
+// Each match is projected to the mutual's profile id and the time of the scanned edge.
+func mutualSummaryPipeline(callerID, targetID primitive.ObjectID, fromCaller bool) mongo.Pipeline {
+	// From the caller: caller -> other, looking up other -> target.
+	// From the target: other -> target, looking up caller -> other.
+	scan := bson.M{"follower_id": callerID, "followee_id": bson.M{"$ne": targetID}}
+	other := "$followee_id"
+	edge := bson.A{
+		bson.M{"$eq": bson.A{"$follower_id", "$$other"}},
+		bson.M{"$eq": bson.A{"$followee_id", targetID}},
+	}
+	if !fromCaller {
+		scan = bson.M{"followee_id": targetID, "follower_id": bson.M{"$ne": callerID}}
+		other = "$follower_id"
+		edge = bson.A{
+			bson.M{"$eq": bson.A{"$follower_id", callerID}},
+			bson.M{"$eq": bson.A{"$followee_id", "$$other"}},
+		}
+	}
+	return mongo.Pipeline{
+		{{Key: "$match", Value: scan}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$limit", Value: mutualScanLimit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "follows",
+			"let":  bson.M{"other": other},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": edge}}},
+				bson.M{"$limit": 1},
+			},
+			"as": "mutual",
+		}}},
+		{{Key: "$match", Value: bson.M{"mutual": bson.M{"$ne": bson.A{}}}}},
+		{{Key: "$project", Value: bson.M{"profile_id": other, "created_at": 1}}},
+	}
+}
+
+// mutualSummary counts the people caller follows who also follow the target and names the most
+// recent few. targetFollowers is the target's follower_count, used to pick the cheaper side.
+func mutualSummary(ctx context.Context, client *mongo.Client, caller model.User, targetID primitive.ObjectID, targetFollowers int64) (MutualSummary, error) {
+	summary := MutualSummary{Profiles: []ProfileSuggestion{}}
+
+	fromCaller := caller.FollowingCount <= targetFollowers
+	scanned := targetFollowers
+	if fromCaller {
+		scanned = caller.FollowingCount
+	}
+	pipeline := append(mutualSummaryPipeline(caller.ID, targetID, fromCaller),
+		bson.D{{Key: "$facet", Value: bson.M{
+			"total":  bson.A{bson.M{"$count": "count"}},
+			"sample": bson.A{bson.M{"$limit": mutualSummarySize}},
+		}}},
+	)
+	cursor, err := followsCollection(client).Aggregate(ctx, pipeline)
+	if err != nil {
+		return summary, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Total  []struct{ Count int64 } `bson:"total"`
+		Sample []struct {
+			ProfileID primitive.ObjectID `bson:"profile_id"`
+		} `bson:"sample"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return summary, err
+	}
+	if len(result) == 0 || len(result[0].Total) == 0 {
+		return summary, nil
+	}
+	summary.Count = result[0].Total[0].Count
+	// Past the scan limit the count is a lower bound, shown as "N+"
+	summary.CountIsLowerBound = scanned > mutualScanLimit
+
+	ids := []primitive.ObjectID{}
+	for _, mutual := range result[0].Sample {
+		ids = append(ids, mutual.ProfileID)
+	}
+	profiles, err := miniProfiles(ctx, client, ids)
+	if err != nil {
+		return summary, err
+	}
+	for _, id := range ids {
+		if profile, ok := profiles[id]; ok {
+			summary.Profiles = append(summary.Profiles, profile)
+		}
+	}
+	return summary, nil
+}
+
+// MutualFollowersHandler handles GET /profile/{id}/mutual
+func MutualFollowersHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		targetID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
+			return
+		}
+		limit := parseLimit(r, defaultFollowListLimit, maxFollowListLimit)
+
+		ctx := context.Background()
+		caller, err := findProfileByUserID(ctx, client, userID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		// Mutuals reveal part of the target's follower list, so they get the same checks as that list
+		target, err := profileVisibility(ctx, client, targetID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		hidden, err := hiddenByBlock(ctx, r, client, targetID)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if hidden {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if !canSee(effectivePrivacy(target).FollowLists, viewerAccess(ctx, r, client, targetID)) {
+			writeJSONError(w, "Follow lists are not visible to you", http.StatusForbidden)
+			return
+		}
+
+		pipeline := mutualFollowersPipeline(caller.ID, targetID)
+		if cursorParam := r.URL.Query().Get("cursor"); cursorParam != "" {
+			cursor, lastID, err := decodeFollowListCursor(cursorParam)
+			if err != nil {
+				writeJSONError(w, "Invalid cursor", http.StatusBadRequest)
+				return
+			}
+			pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+				bson.M{"created_at": bson.M{"$lt": cursor.CreatedAt}},
+				bson.M{"created_at": cursor.CreatedAt, "_id": bson.M{"$lt": lastID}},
+			}}}})
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}}},
+			bson.D{{Key: "$limit", Value: limit + 1}},
+		)
+
+		cur, err := followsCollection(client).Aggregate(ctx, pipeline)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer cur.Close(ctx)
+
+		var edges []Follow
+		if err := cur.All(ctx, &edges); err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		var nextCursor string
+		if len(edges) > limit {
+			edges = edges[:limit]
+			last := edges[limit-1]
+			nextCursor = encodeFollowListCursor(followListCursor{CreatedAt: last.CreatedAt, ID: last.ID.Hex()})
+		}
+
+		ids := make([]primitive.ObjectID, 0, len(edges))
+		for _, edge := range edges {
+			ids = append(ids, edge.FolloweeID)
+		}
+		profiles, err := miniProfiles(ctx, client, ids)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		// Everyone in this list is followed by the caller by construction
+		entries := []FollowListEntry{}
+		for _, edge := range edges {
+			if profile, ok := profiles[edge.FolloweeID]; ok {
+				following := true
+				entries = append(entries, FollowListEntry{ProfileSuggestion: profile, FollowedAt: edge.CreatedAt, IsFollowing: &following})
+			}
+		}
+
+		response := Response{
+			Data: map[string]interface{}{
+				"profiles":    entries,
+				"next_cursor": nextCursor,
+			},
+			Message: "Mutual connections",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+)
+
+// Onboarding steps, in the order new users go through them
+const (
+	OnboardingChannelName     = "channel_name"
+	OnboardingInterests       = "interests"
+	OnboardingFollowSuggested = "follow_suggested"
+	OnboardingNotifications   = "notifications"
+)
+
+var onboardingSteps = []string{OnboardingChannelName, OnboardingInterests, OnboardingFollowSuggested, OnboardingNotifications}
+
+const onboardingSuggestions = 10
+
+// OnboardingStepRequest defines the request structure for PUT /onboarding
+type OnboardingStepRequest struct {
+	Step               string `json:"step"`                           // Required, must be the current step
+	Skip               bool   `json:"skip,omitempty"`                 // Moves past the step without completing it
+	ConfirmChannelName bool   `json:"confirm_channel_name,omitempty"` // Keeps the generated channel name to complete the channel_name step
+	FCMToken           string `json:"fcm_token,omitempty"`            // Required to complete the notifications step
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// currentOnboardingStep returns the first step neither completed nor skipped, or "" when done
+func currentOnboardingStep(o *model.Onboarding) string {
+	if o.Complete() {
+		return ""
+	}
+	for _, step := range onboardingSteps {
+		if !containsString(o.CompletedSteps, step) && !containsString(o.SkippedSteps, step) {
+			return step
+		}
+	}
+	return ""
+}
+
+// onboardingState shapes the onboarding progress of user for API responses
+func onboardingState(ctx context.Context, client *mongo.Client, user model.User) map[string]interface{} {
+	current := currentOnboardingStep(user.Onboarding)
+	steps := []map[string]string{}
+	for _, step := range onboardingSteps {
+		status := "pending"
+		switch {
+		case user.Onboarding == nil || containsString(user.Onboarding.CompletedSteps, step):
+			status = "completed"
+		case containsString(user.Onboarding.SkippedSteps, step):
+			status = "skipped"
+		}
+		steps = append(steps, map[string]string{"step": step, "status": status})
+	}
+
+	state := map[string]interface{}{
+		"steps":               steps,
+		"current_step":        current,
+		"onboarding_complete": user.Onboarding.Complete(),
+	}
+
+	// The follow step shows creators picked from the interests chosen in the step before
+	if current == OnboardingFollowSuggested {
+		suggestions := []ProfileSuggestion{}
+		ids, err := recommendProfiles(ctx, client, user, onboardingSuggestions)
+		if err == nil {
+			profiles, err := miniProfiles(ctx, client, ids)
+			if err == nil {
+				for _, id := range ids {
+					if profile, ok := profiles[id]; ok {
+						suggestions = append(suggestions, profile)
+					}
+				}
+			}
+		}
+		if err != nil {
+			log.Printf("Error loading onboarding suggestions for %s: %v", user.ID.Hex(), err)
+		}
+		state["suggested_profiles"] = suggestions
+	}
+	return state
+}
+
+// followsAnyone reports whether the profile follows or has asked to follow at least one other profile.
+// It looks at the edges rather than following_count, which lags behind and ignores pending requests.
+func followsAnyone(ctx context.Context, client *mongo.Client, profileID primitive.ObjectID) (bool, error) {
+	filter := bson.M{"follower_id": profileID}
+	for _, collection := range []*mongo.Collection{followsCollection(client), followRequestsCollection(client)} {
+		count, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// OnboardingHandler handles GET /onboarding
+func OnboardingHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.Background()
+		user, err := findProfileByUserID(ctx, client, userID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		response := Response{
+			Data:    onboardingState(ctx, client, user),
+			Message: "Onboarding state",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// UpdateOnboardingHandler handles PUT /onboarding, completing or skipping the current step.
+// The data for each step is saved through its own endpoint first; this only checks it is there.
+func UpdateOnboardingHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req OnboardingStepRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.Background()
+		user, err := findProfileByUserID(ctx, client, userID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		current := currentOnboardingStep(user.Onboarding)
+		if current == "" {
+			writeJSONError(w, "Onboarding is already complete", http.StatusConflict)
+			return
+		}
+		if req.Step != current {
+			writeJSONError(w, "Current onboarding step is "+current, http.StatusConflict)
+			return
+		}
+
+		set := bson.M{}
+		if !req.Skip {
+			switch current {
+			case OnboardingChannelName:
+				// Picked means renamed through PATCH /profile or the generated name confirmed here
+				if user.ChannelNameChangedAt == nil && !req.ConfirmChannelName {
+					writeJSONError(w, "Change your channel name through PATCH /profile or send confirm_channel_name to keep "+user.ChannelName, http.StatusUnprocessableEntity)
+					return
+				}
+			case OnboardingInterests:
+				if len(user.AreaOfInterest) == 0 {
+					writeJSONError(w, "Pick at least one interest through PUT /profile/interests first", http.StatusUnprocessableEntity)
+					return
+				}
+			case OnboardingFollowSuggested:
+				following, err := followsAnyone(ctx, client, user.ID)
+				if err != nil {
+					writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+				if !following {
+					writeJSONError(w, "Follow at least one creator first", http.StatusUnprocessableEntity)
+					return
+				}
+			case OnboardingNotifications:
+				if strings.TrimSpace(req.FCMToken) == "" {
+					writeJSONError(w, "fcm_token is required to enable notifications", http.StatusUnprocessableEntity)
+					return
+				}
+				set["fcm_token"] = req.FCMToken
+			}
+		}
+
+		list := "onboarding.completed_steps"
+		if req.Skip {
+			list = "onboarding.skipped_steps"
+		}
+		now := time.Now()
+		set["updated_at"] = now
+		if current == onboardingSteps[len(onboardingSteps)-1] {
+			set["onboarding.completed_at"] = now
+		}
+
+		_, err = profilesCollection(client).UpdateOne(ctx,
+			bson.M{"_id": user.ID},
+			bson.M{"$set": set, "$addToSet": bson.M{list: current}},
+		)
+		if err != nil {
+			writeJSONError(w, "Failed to update onboarding", http.StatusInternalServerError)
+			return
+		}
+
+		user, err = findProfileByUserID(ctx, client, userID)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		response := Response{
+			Data:    onboardingState(ctx, client, user),
+			Message: "Onboarding updated",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
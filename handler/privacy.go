@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+)
+
+// visibilityRank orders the visibility levels; a viewer sees every level up to their own
+var visibilityRank = map[string]int{
+	model.VisibilityPublic:    0,
+	model.VisibilityFollowers: 1,
+	model.VisibilityOnlyMe:    2,
+}
+
+// canSee reports whether a viewer with the given access may see something shared at level
+func canSee(level, access string) bool {
+	return visibilityRank[level] <= visibilityRank[access]
+}
+
+// viewerAccess returns the most restrictive level the optional caller may see on targetID's profile:
+// only_me for the owner, followers for followers and public for everyone else
+func viewerAccess(ctx context.Context, r *http.Request, client *mongo.Client, targetID primitive.ObjectID) string {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		return model.VisibilityPublic
+	}
+	caller, err := findProfileByUserID(ctx, client, userID)
+	if err != nil {
+		return model.VisibilityPublic
+	}
+	if caller.ID == targetID {
+		return model.VisibilityOnlyMe
+	}
+	if following, err := isFollowing(ctx, client, caller.ID, targetID); err == nil && following {
+		return model.VisibilityFollowers
+	}
+	return model.VisibilityPublic
+}
+
+// publicProfileProjection builds the profile projection for a viewer with the given access.
+// Every public handler goes through it so privacy settings are enforced in one place.
+func publicProfileProjection(privacy model.PrivacySettings, access string) bson.M {
+	privacy = privacy.WithDefaults()
+	projection := bson.M{
+		"_id":             1,
+		"name":            1,
+		"bio":             1,
+		"channel_name":    1,
+		"area_of_expert":  1,
+		"profile_picture": 1,
+		"verified":        1,
+		"provider":        1,
+		"follower_count":  1,
+		"following_count": 1,
+	}
+	if canSee(privacy.Email, access) {
+		projection["email"] = 1
+	}
+	if canSee(privacy.Location, access) {
+		projection["location"] = 1
+	}
+	if canSee(privacy.WebAddress, access) {
+		projection["web_address"] = 1
+	}
+	return projection
+}
+
+// profileVisibility loads the privacy settings of a profile that is not banned, along with
+// the fields needed to render its limited card. It always reads the profile collection the
+// rest of the handlers write to, so privacy changes take effect everywhere.
+func profileVisibility(ctx context.Context, client *mongo.Client, id primitive.ObjectID) (model.User, error) {
+	var user model.User
+	err := profilesCollection(client).FindOne(ctx, publicProfileFilter(id), options.FindOne().SetProjection(bson.M{
+		"privacy": 1, "private": 1, "name": 1, "channel_name": 1, "profile_picture": 1, "verified": 1,
+	})).Decode(&user)
+	return user, err
+}
+
+// effectivePrivacy applies the defaults and, for private accounts, limits follow lists and media to followers
+func effectivePrivacy(user model.User) model.PrivacySettings {
+	privacy := user.Privacy.WithDefaults()
+	if user.Private {
+		for _, level := range []*string{&privacy.FollowLists, &privacy.Media} {
+			if !canSee(model.VisibilityFollowers, *level) {
+				*level = model.VisibilityFollowers
+			}
+		}
+	}
+	return privacy
+}
+
+// authorizeMedia runs the checks shared by the media endpoints and writes the response when the
+// viewer may not see targetID's media: users who blocked each other get a 404 and viewers below
+// the media level, including non-followers of private accounts, get writeMediaNotVisible
+func authorizeMedia(w http.ResponseWriter, r *http.Request, client *mongo.Client, targetID primitive.ObjectID) bool {
+	ctx := context.Background()
+	hidden, err := hiddenByBlock(ctx, r, client, targetID)
+	if err != nil {
+		writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+	if hidden {
+		writeJSONError(w, "User not found", http.StatusNotFound)
+		return false
+	}
+
+	target, err := profileVisibility(ctx, client, targetID)
+	if err == mongo.ErrNoDocuments {
+		writeJSONError(w, "User not found", http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+	if !canSee(effectivePrivacy(target).Media, viewerAccess(ctx, r, client, targetID)) {
+		writeMediaNotVisible(w, target)
+		return false
+	}
+	return true
+}
+
+// writeMediaNotVisible answers a media request the viewer may not see. Private accounts
+// get a limited profile card so clients can offer a follow request instead of an error.
+func writeMediaNotVisible(w http.ResponseWriter, user model.User) {
+	if !user.Private {
+		writeJSONError(w, "Media is not visible to you", http.StatusForbidden)
+		return
+	}
+	response := Response{
+		Data: map[string]interface{}{
+			"private": true,
+			"profile": ProfileSuggestion{
+				ID:             user.ID.Hex(),
+				ChannelName:    user.ChannelName,
+				Name:           user.Name,
+				ProfilePicture: user.ProfilePicture,
+				Verified:       user.Verified,
+			},
+		},
+		Message: "This account is private",
+		Status:  true,
+	}
+	writeJSONResponse(w, response, http.StatusOK)
+}
+
+// UpdatePrivacyRequest defines the request structure for PUT /profile/privacy
+type UpdatePrivacyRequest struct {
+	model.PrivacySettings
+	Private *bool `json:"private,omitempty"`
+}
+
+// UpdatePrivacyHandler handles PUT /profile/privacy; omitted fields are left unchanged
+func UpdatePrivacyHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req UpdatePrivacyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		set := bson.M{}
+		for field, level := range map[string]string{
+			"email":        req.Email,
+			"location":     req.Location,
+			"web_address":  req.WebAddress,
+			"follow_lists": req.FollowLists,
+			"media":        req.Media,
+		} {
+			if level == "" {
+				continue
+			}
+			if _, ok := visibilityRank[level]; !ok {
+				writeJSONError(w, field+" must be one of public, followers or only_me", http.StatusBadRequest)
+				return
+			}
+			set["privacy."+field] = level
+		}
+		if req.Private != nil {
+			set["private"] = *req.Private
+		}
+		if len(set) == 0 {
+			writeJSONError(w, "No fields to update", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.Background()
+		var user model.User
+		err := profilesCollection(client).FindOneAndUpdate(ctx,
+			bson.M{"user_id": userID},
+			bson.M{"$set": set},
+			options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"privacy": 1, "private": 1}),
+		).Decode(&user)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Failed to update privacy settings", http.StatusInternalServerError)
+			return
+		}
+
+		// Going public lets everyone who was waiting in
+		if req.Private != nil && !*req.Private {
+			if err := acceptAllFollowRequests(ctx, client, user.ID); err != nil {
+				log.Printf("Error accepting pending follow requests: %v", err)
+			}
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"privacy": user.Privacy.WithDefaults(), "private": user.Private},
+			Message: "Privacy settings updated",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
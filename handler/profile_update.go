@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+	"Backend-Auth-Profiles/utils"
+)
+
+var channelNamePattern = regexp.MustCompile(`^[a-z0-9_.]{3,30}$`)
+
+// allowedLanguages lists the ISO 639-1 codes clients may pick
+var allowedLanguages = map[string]bool{
+	"en": true, "hi": true, "bn": true, "ta": true, "te": true, "mr": true,
+	"es": true, "fr": true, "de": true, "pt": true, "it": true, "ar": true,
+	"ja": true, "ko": true, "zh": true, "ru": true,
+}
+
+// UpdateProfileRequest defines the request structure for PATCH /profile; omitted fields are left unchanged
+type UpdateProfileRequest struct {
+	Name         *string   `json:"name,omitempty"`
+	Bio          *string   `json:"bio,omitempty"`
+	WebAddress   *string   `json:"web_address,omitempty"`
+	Location     *string   `json:"location,omitempty"`
+	Language     *string   `json:"language,omitempty"`
+	AreaOfExpert *[]string `json:"area_of_expert,omitempty"`
+	ChannelName  *string   `json:"channel_name,omitempty"`
+	Version      *int64    `json:"version"` // Required, version of the profile the client edited
+}
+
+// validate checks every provided field and returns the $set document for the update
+func (req UpdateProfileRequest) validate() (bson.M, map[string]string) {
+	set := bson.M{}
+	errs := map[string]string{}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if n := utf8.RuneCountInString(name); n < 1 || n > 50 {
+			errs["name"] = "must be between 1 and 50 characters"
+		}
+		set["name"] = name
+		set["name_norm"] = normalizePrefix(name)
+	}
+	if req.Bio != nil {
+		if utf8.RuneCountInString(*req.Bio) > 300 {
+			errs["bio"] = "must be at most 300 characters"
+		}
+		set["bio"] = *req.Bio
+	}
+	if req.WebAddress != nil {
+		web := strings.TrimSpace(*req.WebAddress)
+		if web != "" {
+			u, err := url.ParseRequestURI(web)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(web) > 200 {
+				errs["web_address"] = "must be an http or https URL of at most 200 characters"
+			}
+		}
+		set["web_address"] = web
+	}
+	if req.Location != nil {
+		location := strings.TrimSpace(*req.Location)
+		if utf8.RuneCountInString(location) > 100 {
+			errs["location"] = "must be at most 100 characters"
+		}
+		set["location"] = location
+	}
+	if req.Language != nil {
+		if !allowedLanguages[*req.Language] {
+			errs["language"] = "is not a supported language code"
+		}
+		set["language"] = *req.Language
+	}
+	if req.AreaOfExpert != nil {
+		// Expertise is validated against the taxonomy, which needs the database
+		errs["area_of_expert"] = "must be updated through PUT /profile/expertise"
+	}
+	if req.ChannelName != nil {
+		if !channelNamePattern.MatchString(*req.ChannelName) {
+			errs["channel_name"] = "must be 3-30 characters of lowercase letters, digits, underscores or dots"
+		}
+		set["channel_name"] = *req.ChannelName
+		set["channel_name_norm"] = *req.ChannelName
+	}
+	return set, errs
+}
+
+// UpdateProfileHandler handles PATCH /profile
+func UpdateProfileHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req UpdateProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Version == nil {
+			writeJSONError(w, "version is required", http.StatusBadRequest)
+			return
+		}
+
+		set, errs := req.validate()
+		if len(errs) > 0 {
+			response := Response{
+				Data:    map[string]interface{}{"errors": errs},
+				Message: "Validation failed",
+				Status:  false,
+			}
+			writeJSONResponse(w, response, http.StatusUnprocessableEntity)
+			return
+		}
+		if len(set) == 0 {
+			writeJSONError(w, "No fields to update", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.Background()
+		collection := profilesCollection(client)
+		current, err := findProfileByUserID(ctx, client, userID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if channelName, ok := set["channel_name"].(string); ok {
+			if channelName == current.ChannelName {
+				delete(set, "channel_name")
+				delete(set, "channel_name_norm")
+			} else {
+				// Changing the public handle is a sensitive operation
+				if !utils.HasRecentAuth(r, utils.SensitiveAuthMaxAge) {
+					utils.WriteReauthRequired(w, utils.SensitiveAuthMaxAge)
+					return
+				}
+				if current.ChannelNameChangedAt != nil && time.Since(*current.ChannelNameChangedAt) < channelNameRenameCooldown {
+					nextChange := current.ChannelNameChangedAt.Add(channelNameRenameCooldown)
+					writeJSONError(w, "channel_name can be changed again after "+nextChange.Format(time.RFC3339), http.StatusTooManyRequests)
+					return
+				}
+				available, reason, err := channelNameAvailability(ctx, client, channelName, current.ID)
+				if err != nil {
+					writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+				if !available {
+					writeJSONError(w, "channel_name is not available: "+reason, http.StatusConflict)
+					return
+				}
+				set["channel_name_changed_at"] = time.Now()
+			}
+		}
+
+		// Optimistic concurrency: only apply the update if no other edit landed since the client read
+		// the profile. version only moves here, so logins and background jobs never cause a conflict.
+		set["updated_at"] = time.Now()
+		filter := bson.M{"_id": current.ID, "version": *req.Version}
+		if *req.Version == 0 {
+			// Profiles that were never edited have no version field yet
+			filter["version"] = bson.M{"$in": bson.A{0, nil}}
+		}
+		var user model.User
+		err = collection.FindOneAndUpdate(ctx,
+			filter,
+			bson.M{"$set": set, "$inc": bson.M{"version": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&user)
+		if mongo.IsDuplicateKeyError(err) {
+			writeJSONError(w, "channel_name is not available: taken", http.StatusConflict)
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, fmt.Sprintf("Profile was modified (now at version %d); reload and try again", current.Version), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Failed to update profile: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if user.ChannelName != current.ChannelName {
+			if err := holdChannelName(ctx, client, current.ChannelName, current.ID); err != nil {
+				log.Printf("Error holding released channel name %s: %v", current.ChannelName, err)
+			}
+		}
+
+		response := Response{
+			Data:    ownProfileData(user),
+			Message: "Profile updated",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// ownProfileData shapes the caller's own profile for API responses
+func ownProfileData(user model.User) map[string]interface{} {
+	return map[string]interface{}{
+		"_id":                 user.ID.Hex(),
+		"email":               user.Email,
+		"name":                user.Name,
+		"bio":                 user.Bio,
+		"web_address":         user.WebAddress,
+		"location":            user.Location,
+		"language":            user.Language,
+		"channel_name":        user.ChannelName,
+		"area_of_expert":      user.AreaOfExpert,
+		"area_of_interest":    user.AreaOfInterest,
+		"profile_picture":     user.ProfilePicture,
+		"verified":            user.Verified,
+		"provider":            user.Provider,
+		"follower_count":      user.FollowerCount,
+		"following_count":     user.FollowingCount,
+		"privacy":             user.Privacy.WithDefaults(),
+		"private":             user.Private,
+		"onboarding_complete": user.Onboarding.Complete(),
+		"updated_at":          user.UpdatedAt,
+		"version":             user.Version,
+	}
+}
@@ -11,30 +11,6 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// PublicProfileFields defines the fields to retrieve for PublicProfile
-var PublicProfileFields = bson.M{
-	"_id":            1,
-	"email":          1,
-	"name":           1,
-	"bio":            1,
-	"web_address":    1,
-	"channel_name":   1,
-	"area_of_expert": 1,
-	"profile_picture": 1,
-	"verified":       1,
-	"location":       1,
-	"provider":       1,
-	"follower":       1,
-	"following":      1,
-}
-
-// PublicProfileMedia2Fields defines the fields for PublicProfileMedia2
-var PublicProfileMedia2Fields = bson.M{
-	"_id":   1,
-	"email": 1,
-	"name":  1,
-}
-
 // Room represents a room document
 type Room struct {
 	ID        primitive.ObjectID `bson:"_id"`
@@ -59,15 +35,8 @@ func ProfileHandler(client *mongo.Client) http.HandlerFunc {
 			return
 		}
 
-		collection := client.Database("auth").Collection("profile")
-		var user User
-		objID, err := primitive.ObjectIDFromHex(userID)
-		if err != nil {
-			writeJSONError(w, "Invalid user ID", http.StatusBadRequest)
-			return
-		}
-
-		err = collection.FindOne(context.Background(), bson.M{"_id": objID}, options.FindOne().SetProjection(PublicProfileFields)).Decode(&user)
+		// The token carries the provider user id, so the owner's profile is looked up by user_id
+		user, err := findProfileByUserID(context.Background(), client, userID)
 		if err == mongo.ErrNoDocuments {
 			writeJSONError(w, "User not found", http.StatusNotFound)
 			return
@@ -77,21 +46,8 @@ func ProfileHandler(client *mongo.Client) http.HandlerFunc {
 			return
 		}
 
-		data := map[string]interface{}{
-			"_id":            user.ID.Hex(),
-			"email":          user.Email,
-			"name":           user.Name,
-			"bio":            user.Bio,
-			"web_address":    user.WebAddress,
-			"channel_name":   user.ChannelName,
-			"area_of_expert": user.AreaOfExpert,
-			"profile_picture": user.ProfilePicture,
-			"verified":       user.Verified,
-			"location":       user.Location,
-			"provider":       user.Provider,
-			"follower_count": len(user.Followers),
-			"following_count": len(user.Following),
-		}
+		data := ownProfileData(user)
+		data["completeness"], data["missing"] = profileCompleteness(user)
 
 		response := Response{
 			Data:    data,
@@ -116,10 +72,20 @@ func PublicProfileHandler(client *mongo.Client) http.HandlerFunc {
 			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
 			return
 		}
+		// Users who blocked each other cannot see each other's profile or media
+		hidden, err := hiddenByBlock(context.Background(), r, client, objID)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if hidden {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
 
-		collection := client.Database("auth").Collection("profile")
-		var user User
-		err = collection.FindOne(context.Background(), bson.M{"_id": objID}, options.FindOne().SetProjection(PublicProfileFields)).Decode(&user)
+		collection := profilesCollection(client)
+		filter := publicProfileFilter(objID)
+		target, err := profileVisibility(context.Background(), client, objID)
 		if err == mongo.ErrNoDocuments {
 			writeJSONError(w, "User not found", http.StatusNotFound)
 			return
@@ -129,30 +95,32 @@ func PublicProfileHandler(client *mongo.Client) http.HandlerFunc {
 			return
 		}
 
-		data := map[string]interface{}{
-			"_id":            user.ID.Hex(),
-			"email":          user.Email,
-			"name":           user.Name,
-			"bio":            user.Bio,
-			"web_address":    user.WebAddress,
-			"channel_name":   user.ChannelName,
-			"area_of_expert": user.AreaOfExpert,
-			"profile_picture": user.ProfilePicture,
-			"verified":       user.Verified,
-			"location":       user.Location,
-			"provider":       user.Provider,
-			"follower_count": len(user.Followers),
-			"following_count": len(user.Following),
+		var user User
+		access := viewerAccess(context.Background(), r, client, objID)
+		projection := publicProfileProjection(target.Privacy, access)
+		err = collection.FindOne(context.Background(), filter, options.FindOne().SetProjection(projection)).Decode(&user)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
 		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		data := publicProfileData(user, projection)
 		if userID, ok := r.Context().Value("userID").(string); ok {
-			isFollowing := false
-			for _, follower := range user.Followers {
-				if follower == userID {
-					isFollowing = true
-					break
+			isFollowingUser := false
+			if caller, err := findProfileByUserID(context.Background(), client, userID); err == nil && caller.ID != user.ID {
+				isFollowingUser, _ = isFollowing(context.Background(), client, caller.ID, user.ID)
+				// Mutuals are part of the follower list, so they follow its visibility
+				if canSee(effectivePrivacy(target).FollowLists, access) {
+					if summary, err := mutualSummary(context.Background(), client, caller, user.ID, user.FollowerCount); err == nil {
+						data["followed_by"] = summary
+					}
 				}
 			}
-			data["is_following"] = isFollowing
+			data["is_following"] = isFollowingUser
 		}
 
 		response := Response{
@@ -178,15 +146,8 @@ func PublicProfileMediaHandler(client *mongo.Client) http.HandlerFunc {
 			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
 			return
 		}
-		collection := client.Database("auth").Collection("profile")
-		var user bson.M
-		err = collection.FindOne(context.Background(), bson.M{"_id": objID}).Decode(&user)
-		if err == mongo.ErrNoDocuments {
-			writeJSONError(w, "User not found", http.StatusNotFound)
-			return
-		}
-		if err != nil {
-			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		// Blocks, media visibility and private accounts are all enforced by authorizeMedia
+		if !authorizeMedia(w, r, client, objID) {
 			return
 		}
 
@@ -248,17 +209,8 @@ func PublicProfileMediaLiveHandler(client *mongo.Client) http.HandlerFunc {
 			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
 			return
 		}
-
-		// Check if user exists
-		collection := client.Database("auth").Collection("profile")
-		var user bson.M
-		err = collection.FindOne(context.Background(), bson.M{"_id": objID}).Decode(&user)
-		if err == mongo.ErrNoDocuments {
-			writeJSONError(w, "User not found", http.StatusNotFound)
-			return
-		}
-		if err != nil {
-			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		// Blocks, media visibility and private accounts are all enforced by authorizeMedia
+		if !authorizeMedia(w, r, client, objID) {
 			return
 		}
 
@@ -304,16 +256,8 @@ func PublicProfileMediaUpcomingHandler(client *mongo.Client) http.HandlerFunc {
 			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
 			return
 		}
-
-		collection := client.Database("auth").Collection("profile")
-		var user bson.M
-		err = collection.FindOne(context.Background(), bson.M{"_id": objID}).Decode(&user)
-		if err == mongo.ErrNoDocuments {
-			writeJSONError(w, "User not found", http.StatusNotFound)
-			return
-		}
-		if err != nil {
-			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		// Blocks, media visibility and private accounts are all enforced by authorizeMedia
+		if !authorizeMedia(w, r, client, objID) {
 			return
 		}
 		roomCollection := client.Database("myspace").Collection("rooms")
@@ -366,10 +310,19 @@ func PublicProfileMedia2Handler(client *mongo.Client) http.HandlerFunc {
 			writeJSONError(w, "Invalid id format", http.StatusBadRequest)
 			return
 		}
+		// Users who blocked each other cannot see each other's profile or media
+		hidden, err := hiddenByBlock(context.Background(), r, client, objID)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if hidden {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
 
-		collection := client.Database("auth").Collection("profile")
-		var user User
-		err = collection.FindOne(context.Background(), bson.M{"_id": objID}, options.FindOne().SetProjection(PublicProfileMedia2Fields)).Decode(&user)
+		collection := profilesCollection(client)
+		target, err := profileVisibility(context.Background(), client, objID)
 		if err == mongo.ErrNoDocuments {
 			writeJSONError(w, "User not found", http.StatusNotFound)
 			return
@@ -379,10 +332,20 @@ func PublicProfileMedia2Handler(client *mongo.Client) http.HandlerFunc {
 			return
 		}
 
+		var user User
+		projection := publicProfileProjection(target.Privacy, viewerAccess(context.Background(), r, client, objID))
+		err = collection.FindOne(context.Background(), publicProfileFilter(objID), options.FindOne().SetProjection(projection)).Decode(&user)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
 		data := map[string]interface{}{
-			"_id":   user.ID.Hex(),
-			"email": user.Email,
-			"name":  user.Name,
+			"_id":  user.ID.Hex(),
+			"name": user.Name,
+		}
+		if _, ok := projection["email"]; ok {
+			data["email"] = user.Email
 		}
 
 		response := Response{
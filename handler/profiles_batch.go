@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+)
+
+const maxBatchProfiles = 100
+
+// BatchProfilesRequest defines the request structure for POST /profiles/batch
+type BatchProfilesRequest struct {
+	IDs          []string `json:"ids,omitempty"`           // Profile ids
+	ChannelNames []string `json:"channel_names,omitempty"` // Matched case-insensitively
+}
+
+// batchProfile is a profile loaded with everything any viewer could be shown, plus its privacy settings
+// and the requested channel names it matched under the case-insensitive collation
+type batchProfile struct {
+	User         `bson:",inline"`
+	Private      bool     `bson:"private"`
+	MatchedNames []string `bson:"matched_names"`
+}
+
+// BatchProfilesHandler handles POST /profiles/batch. Profiles come back with the same fields /public
+// would show the caller, those requested by id first and then those requested by channel name, each
+// in request order. Ids and channel names that match nothing visible are listed under missing exactly
+// as they were sent.
+func BatchProfilesHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req BatchProfilesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if len(req.IDs)+len(req.ChannelNames) == 0 {
+			writeJSONError(w, "ids or channel_names is required", http.StatusBadRequest)
+			return
+		}
+		if len(req.IDs)+len(req.ChannelNames) > maxBatchProfiles {
+			writeJSONError(w, "At most 100 ids and channel names can be requested at once", http.StatusBadRequest)
+			return
+		}
+
+		// Keys are kept in request order so results line up with what the client asked for, and each
+		// remembers the string it was sent as so missing echoes it back unchanged
+		missing := []string{}
+		ids := []primitive.ObjectID{}
+		idInputs := map[primitive.ObjectID]string{}
+		for _, hexID := range req.IDs {
+			id, err := primitive.ObjectIDFromHex(hexID)
+			if err != nil {
+				missing = append(missing, hexID)
+				continue
+			}
+			if _, ok := idInputs[id]; !ok {
+				idInputs[id] = hexID
+				ids = append(ids, id)
+			}
+		}
+		names := []string{}
+		nameInputs := map[string]string{}
+		for _, input := range req.ChannelNames {
+			name := strings.TrimSpace(input)
+			if name == "" {
+				missing = append(missing, input)
+				continue
+			}
+			if _, ok := nameInputs[name]; !ok {
+				nameInputs[name] = input
+				names = append(names, name)
+			}
+		}
+
+		ctx := context.Background()
+		var caller *model.User
+		if userID, ok := r.Context().Value("userID").(string); ok {
+			if user, err := findProfileByUserID(ctx, client, userID); err == nil {
+				caller = &user
+			} else if err != mongo.ErrNoDocuments {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Banned profiles and anyone on either side of a block with the caller are reported as missing
+		conditions := bson.A{bson.M{"suspension.type": bson.M{"$ne": "banned"}}}
+		if caller != nil {
+			blocked, err := blockedProfileIDs(ctx, client, caller.ID)
+			if err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if len(blocked) > 0 {
+				conditions = append(conditions, bson.M{"_id": bson.M{"$nin": blocked}})
+			}
+		}
+		or := bson.A{}
+		if len(ids) > 0 {
+			or = append(or, bson.M{"_id": bson.M{"$in": ids}})
+		}
+		if len(names) > 0 {
+			or = append(or, bson.M{"channel_name": bson.M{"$in": names}})
+		}
+		conditions = append(conditions, bson.M{"$or": or})
+
+		// The requested names each profile matched are worked out by the server under the same
+		// collation as the query, so names differing only in case map back to the profile they found
+		projection := publicProfileProjection(model.PrivacySettings{}, model.VisibilityOnlyMe)
+		projection["privacy"] = 1
+		projection["private"] = 1
+		projection["matched_names"] = bson.M{"$filter": bson.M{
+			"input": bson.M{"$literal": names},
+			"cond":  bson.M{"$eq": bson.A{"$$this", "$channel_name"}},
+		}}
+		cursor, err := profilesCollection(client).Aggregate(ctx,
+			mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"$and": conditions}}},
+				{{Key: "$project", Value: projection}},
+			},
+			options.Aggregate().SetCollation(&caseInsensitive),
+		)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer cursor.Close(ctx)
+
+		var loaded []batchProfile
+		if err := cursor.All(ctx, &loaded); err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		byID := map[primitive.ObjectID]batchProfile{}
+		byName := map[string]batchProfile{}
+		found := make([]primitive.ObjectID, 0, len(loaded))
+		for _, profile := range loaded {
+			byID[profile.ID] = profile
+			for _, name := range profile.MatchedNames {
+				byName[name] = profile
+			}
+			found = append(found, profile.ID)
+		}
+
+		followed := map[primitive.ObjectID]bool{}
+		if caller != nil {
+			followed, err = followedAmong(ctx, client, caller.ID, found)
+			if err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		profiles := []map[string]interface{}{}
+		returned := map[primitive.ObjectID]bool{}
+		appendProfile := func(profile batchProfile) {
+			if returned[profile.ID] {
+				return
+			}
+			returned[profile.ID] = true
+
+			access := model.VisibilityPublic
+			switch {
+			case caller != nil && caller.ID == profile.ID:
+				access = model.VisibilityOnlyMe
+			case followed[profile.ID]:
+				access = model.VisibilityFollowers
+			}
+			data := publicProfileData(profile.User, publicProfileProjection(profile.Privacy, access))
+			data["private"] = profile.Private
+			if caller != nil {
+				data["is_following"] = followed[profile.ID]
+			}
+			profiles = append(profiles, data)
+		}
+		for _, id := range ids {
+			if profile, ok := byID[id]; ok {
+				appendProfile(profile)
+			} else {
+				missing = append(missing, idInputs[id])
+			}
+		}
+		for _, name := range names {
+			if profile, ok := byName[name]; ok {
+				appendProfile(profile)
+			} else {
+				missing = append(missing, nameInputs[name])
+			}
+		}
+
+		response := Response{
+			Data: map[string]interface{}{
+				"profiles": profiles,
+				"missing":  missing,
+			},
+			Message: "Profiles Extracted",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
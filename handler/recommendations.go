@@ -0,0 +1,365 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+)
+
+const (
+	defaultRecommendationLimit = 20
+	maxRecommendationLimit     = 50
+	storedRecommendations      = 50
+	recommendationCandidates   = 500
+	recentLiveWindow           = 14 * 24 * time.Hour
+	defaultRecommendationHour  = 3
+	recommendationLeaseTTL     = 23 * time.Hour // Shorter than a day so the next run is never locked out
+
+	// Score weights: interest overlap dominates, the follow graph and activity break ties
+	interestOverlapWeight = 3.0
+	followProximityWeight = 2.0
+	liveNowWeight         = 2.0
+	recentRoomWeight      = 0.5
+	maxRecentRooms        = 5
+)
+
+type recommendationCandidate struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	AreaOfExpert []string           `bson:"area_of_expert"`
+	Live         bool               `bson:"live"`
+	proximity    int64
+	recentRooms  int64
+}
+
+func (c recommendationCandidate) score(interests map[string]bool) float64 {
+	overlap := 0
+	for _, slug := range c.AreaOfExpert {
+		if interests[slug] {
+			overlap++
+		}
+	}
+	score := interestOverlapWeight*float64(overlap) + followProximityWeight*math.Log1p(float64(c.proximity))
+	if c.Live {
+		score += liveNowWeight
+	}
+	return score + recentRoomWeight*math.Min(float64(c.recentRooms), maxRecentRooms)
+}
+
+// interestSlugs flattens an area_of_interest tree into the set of slugs it mentions
+func interestSlugs(interests map[string]map[string][]string) map[string]bool {
+	slugs := map[string]bool{}
+	for branch, categories := range interests {
+		slugs[branch] = true
+		for category, subcategories := range categories {
+			slugs[category] = true
+			for _, subcategory := range subcategories {
+				slugs[subcategory] = true
+			}
+		}
+	}
+	return slugs
+}
+
+// excludedFromRecommendations is the user, everyone they follow and everyone on either side of a block
+func excludedFromRecommendations(ctx context.Context, client *mongo.Client, user model.User) ([]primitive.ObjectID, []primitive.ObjectID, error) {
+	cursor, err := followsCollection(client).Find(ctx, bson.M{"follower_id": user.ID}, options.Find().SetProjection(bson.M{"followee_id": 1}))
+	if err != nil {
+		return nil, nil, err
+	}
+	var edges []Follow
+	if err := cursor.All(ctx, &edges); err != nil {
+		return nil, nil, err
+	}
+	followees := make([]primitive.ObjectID, 0, len(edges))
+	for _, edge := range edges {
+		followees = append(followees, edge.FolloweeID)
+	}
+
+	blocked, err := blockedProfileIDs(ctx, client, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	excluded := append([]primitive.ObjectID{user.ID}, followees...)
+	return append(excluded, blocked...), followees, nil
+}
+
+// recommendProfiles ranks creators for user by interest overlap, follow-graph proximity and recent live activity
+func recommendProfiles(ctx context.Context, client *mongo.Client, user model.User, limit int) ([]primitive.ObjectID, error) {
+	excluded, followees, err := excludedFromRecommendations(ctx, client, user)
+	if err != nil {
+		return nil, err
+	}
+	interests := interestSlugs(user.AreaOfInterest)
+	candidates := map[primitive.ObjectID]*recommendationCandidate{}
+	profiles := profilesCollection(client)
+	visible := bson.M{"_id": bson.M{"$nin": excluded}, "suspension.type": bson.M{"$ne": "banned"}}
+
+	// Creators followed by people the user follows
+	if len(followees) > 0 {
+		cursor, err := followsCollection(client).Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"follower_id": bson.M{"$in": followees}, "followee_id": bson.M{"$nin": excluded}}}},
+			{{Key: "$group", Value: bson.M{"_id": "$followee_id", "count": bson.M{"$sum": 1}}}},
+			{{Key: "$sort", Value: bson.M{"count": -1}}},
+			{{Key: "$limit", Value: recommendationCandidates}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var groups []struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Count int64              `bson:"count"`
+		}
+		if err := cursor.All(ctx, &groups); err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			candidates[group.ID] = &recommendationCandidate{ID: group.ID, proximity: group.Count}
+		}
+	}
+
+	// Creators whose expertise matches the user's interests, plus whoever is live right now
+	or := bson.A{bson.M{"live": true}}
+	if len(interests) > 0 {
+		slugs := make([]string, 0, len(interests))
+		for slug := range interests {
+			slugs = append(slugs, slug)
+		}
+		or = append(or, bson.M{"area_of_expert": bson.M{"$in": slugs}})
+	}
+	ids := make([]primitive.ObjectID, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	or = append(or, bson.M{"_id": bson.M{"$in": ids}})
+
+	filter := bson.M{"$and": bson.A{visible, bson.M{"$or": or}}}
+	cursor, err := profiles.Find(ctx, filter, options.Find().
+		SetProjection(bson.M{"_id": 1, "area_of_expert": 1, "live": 1}).
+		SetSort(bson.M{"follower_count": -1}).
+		SetLimit(2*recommendationCandidates))
+	if err != nil {
+		return nil, err
+	}
+	var loaded []recommendationCandidate
+	if err := cursor.All(ctx, &loaded); err != nil {
+		return nil, err
+	}
+
+	// Only profiles that passed the visibility filter survive
+	ranked := make([]*recommendationCandidate, 0, len(loaded))
+	ids = ids[:0]
+	for i := range loaded {
+		candidate := &loaded[i]
+		if existing, ok := candidates[candidate.ID]; ok {
+			candidate.proximity = existing.proximity
+		}
+		ranked = append(ranked, candidate)
+		ids = append(ids, candidate.ID)
+	}
+	if len(ranked) == 0 {
+		return []primitive.ObjectID{}, nil
+	}
+
+	// Rooms are scheduled in milliseconds since the epoch; upcoming rooms are not activity yet
+	now := time.Now()
+	since := now.Add(-recentLiveWindow).UnixMilli()
+	cursor, err = client.Database("myspace").Collection("rooms").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"creator._id": bson.M{"$in": ids}, "schedule": bson.M{"$gte": since, "$lte": now.UnixMilli()}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$creator._id", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rooms []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rooms); err != nil {
+		return nil, err
+	}
+	recent := map[primitive.ObjectID]int64{}
+	for _, room := range rooms {
+		recent[room.ID] = room.Count
+	}
+
+	scores := map[primitive.ObjectID]float64{}
+	for _, candidate := range ranked {
+		candidate.recentRooms = recent[candidate.ID]
+		scores[candidate.ID] = candidate.score(interests)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].ID] > scores[ranked[j].ID]
+	})
+
+	result := []primitive.ObjectID{}
+	for _, candidate := range ranked {
+		if len(result) == limit {
+			break
+		}
+		if scores[candidate.ID] > 0 {
+			result = append(result, candidate.ID)
+		}
+	}
+	return result, nil
+}
+
+// recommendationHour reads RECOMMENDATION_HOUR, the UTC hour of the nightly refresh, falling back to the default
+func recommendationHour() int {
+	hour, err := strconv.Atoi(os.Getenv("RECOMMENDATION_HOUR"))
+	if err != nil || hour < 0 || hour > 23 {
+		hour = defaultRecommendationHour
+	}
+	return hour
+}
+
+// nextRecommendationRun returns the first refresh slot after now
+func nextRecommendationRun(now time.Time) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), recommendationHour(), 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// StartRecommendationWorker recomputes every user's recommendations into profile_of_interest once
+// a day at RECOMMENDATION_HOUR. Every instance schedules the run, and the job lease lets only one do it.
+func StartRecommendationWorker(client *mongo.Client) {
+	go func() {
+		for {
+			time.Sleep(time.Until(nextRecommendationRun(time.Now())))
+			acquired, err := acquireJobLease(context.Background(), client, "recommendations", recommendationLeaseTTL)
+			if err != nil {
+				log.Printf("Error acquiring recommendation lease: %v", err)
+				continue
+			}
+			if !acquired {
+				continue
+			}
+			refreshRecommendations(client)
+		}
+	}()
+}
+
+func refreshRecommendations(client *mongo.Client) {
+	ctx := context.Background()
+	collection := profilesCollection(client)
+	cursor, err := collection.Find(ctx,
+		bson.M{"suspension.type": bson.M{"$ne": "banned"}, "deletion_scheduled_at": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"_id": 1, "area_of_interest": 1}),
+	)
+	if err != nil {
+		log.Printf("Error loading profiles for recommendations: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	refreshed := 0
+	for cursor.Next(ctx) {
+		var user model.User
+		if err := cursor.Decode(&user); err != nil {
+			log.Printf("Error decoding profile for recommendations: %v", err)
+			continue
+		}
+		ids, err := recommendProfiles(ctx, client, user, storedRecommendations)
+		if err != nil {
+			log.Printf("Error computing recommendations for %s: %v", user.ID.Hex(), err)
+			continue
+		}
+		hexIDs := make([]string, 0, len(ids))
+		for _, id := range ids {
+			hexIDs = append(hexIDs, id.Hex())
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"profile_of_interest": hexIDs}}); err != nil {
+			log.Printf("Error storing recommendations for %s: %v", user.ID.Hex(), err)
+			continue
+		}
+		refreshed++
+	}
+	log.Printf("Refreshed recommendations for %d profiles", refreshed)
+}
+
+// RecommendedProfilesHandler handles GET /recommendations/profiles. It serves the nightly
+// profile_of_interest list and falls back to computing one for users the job has not reached yet.
+func RecommendedProfilesHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		limit := parseLimit(r, defaultRecommendationLimit, maxRecommendationLimit)
+
+		ctx := context.Background()
+		user, err := findProfileByUserID(ctx, client, userID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		var ids []primitive.ObjectID
+		if len(user.ProfileOfInterest) > 0 {
+			// The stored list can be up to a day old, so drop anyone followed or blocked since
+			excluded, _, err := excludedFromRecommendations(ctx, client, user)
+			if err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			skip := map[primitive.ObjectID]bool{}
+			for _, id := range excluded {
+				skip[id] = true
+			}
+			for _, hexID := range user.ProfileOfInterest {
+				id, err := primitive.ObjectIDFromHex(hexID)
+				if err != nil || skip[id] {
+					continue
+				}
+				ids = append(ids, id)
+				if len(ids) == limit {
+					break
+				}
+			}
+		} else {
+			ids, err = recommendProfiles(ctx, client, user, limit)
+			if err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		profiles, err := miniProfiles(ctx, client, ids)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		recommendations := []ProfileSuggestion{}
+		for _, id := range ids {
+			if profile, ok := profiles[id]; ok {
+				recommendations = append(recommendations, profile)
+			}
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"profiles": recommendations},
+			Message: "Recommended profiles",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
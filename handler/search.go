@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	model "Backend-Auth-Profiles/models"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+// searchCursor marks the last result of a page; the next page starts strictly after it
+type searchCursor struct {
+	Score float64 `json:"s"`
+	ID    string  `json:"id"`
+}
+
+// profileSearchResult is a public profile with its relevance score
+type profileSearchResult struct {
+	User  `bson:",inline"`
+	Score float64 `bson:"score"`
+}
+
+func encodeSearchCursor(c searchCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSearchCursor(s string) (searchCursor, primitive.ObjectID, error) {
+	var c searchCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, primitive.NilObjectID, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, primitive.NilObjectID, err
+	}
+	id, err := primitive.ObjectIDFromHex(c.ID)
+	return c, id, err
+}
+
+// parseLimit reads ?limit= bounded by max, falling back to def
+func parseLimit(r *http.Request, def, max int) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return def
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
+
+// SearchProfilesHandler handles GET /search/profiles
+func SearchProfilesHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		q := strings.TrimSpace(query.Get("q"))
+		if q == "" {
+			writeJSONError(w, "Missing q parameter", http.StatusBadRequest)
+			return
+		}
+		limit := parseLimit(r, defaultSearchLimit, maxSearchLimit)
+
+		match := bson.M{
+			"$text":           bson.M{"$search": q},
+			"suspension.type": bson.M{"$ne": "banned"},
+		}
+		blocked, err := callerBlockedIDs(context.Background(), r, client)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if len(blocked) > 0 {
+			match["_id"] = bson.M{"$nin": blocked}
+		}
+		if v := query.Get("verified"); v != "" {
+			match["verified"] = v == "true"
+		}
+		if v := query.Get("live"); v != "" {
+			match["live"] = v == "true"
+		}
+		if v := query.Get("language"); v != "" {
+			match["language"] = v
+		}
+		if v := query.Get("location"); v != "" {
+			match["location"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(v), Options: "i"}
+			// Filtering on a hidden location would reveal it through who comes back, so only
+			// public locations are searchable; a missing setting defaults to public
+			match["privacy.location"] = bson.M{"$in": bson.A{nil, "", model.VisibilityPublic}}
+		}
+
+		// Load every field a profile may share; publicProfileData redacts per profile below
+		projection := publicProfileProjection(model.PrivacySettings{}, model.VisibilityOnlyMe)
+		projection["score"] = 1
+		projection["privacy"] = 1
+
+		// Relevance is the text score boosted by the log of the follower count
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: match}},
+			{{Key: "$addFields", Value: bson.M{
+				"score": bson.M{"$multiply": bson.A{
+					bson.M{"$meta": "textScore"},
+					bson.M{"$add": bson.A{1, bson.M{"$log10": bson.M{"$add": bson.A{1, bson.M{"$ifNull": bson.A{"$follower_count", 0}}}}}}},
+				}},
+			}}},
+		}
+		if cursorParam := query.Get("cursor"); cursorParam != "" {
+			cursor, lastID, err := decodeSearchCursor(cursorParam)
+			if err != nil {
+				writeJSONError(w, "Invalid cursor", http.StatusBadRequest)
+				return
+			}
+			pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+				bson.M{"score": bson.M{"$lt": cursor.Score}},
+				bson.M{"score": cursor.Score, "_id": bson.M{"$lt": lastID}},
+			}}}})
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}}}},
+			bson.D{{Key: "$limit", Value: limit + 1}},
+			bson.D{{Key: "$project", Value: projection}},
+		)
+
+		ctx := context.Background()
+		collection := profilesCollection(client)
+		cur, err := collection.Aggregate(ctx, pipeline)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer cur.Close(ctx)
+
+		var results []profileSearchResult
+		if err := cur.All(ctx, &results); err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		var nextCursor string
+		if len(results) > limit {
+			results = results[:limit]
+			last := results[limit-1]
+			nextCursor = encodeSearchCursor(searchCursor{Score: last.Score, ID: last.ID.Hex()})
+		}
+
+		profiles := []map[string]interface{}{}
+		for _, result := range results {
+			profiles = append(profiles, publicProfileData(result.User, publicProfileProjection(result.Privacy, model.VisibilityPublic)))
+		}
+
+		response := Response{
+			Data: map[string]interface{}{
+				"profiles":    profiles,
+				"next_cursor": nextCursor,
+			},
+			Message: "Search results",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// publicProfileData shapes a profile for API responses, keeping only the fields in projection
+func publicProfileData(user User, projection bson.M) map[string]interface{} {
+	data := map[string]interface{}{
+		"_id":             user.ID.Hex(),
+		"email":           user.Email,
+		"name":            user.Name,
+		"bio":             user.Bio,
+		"web_address":     user.WebAddress,
+		"channel_name":    user.ChannelName,
+		"area_of_expert":  user.AreaOfExpert,
+		"profile_picture": user.ProfilePicture,
+		"verified":        user.Verified,
+		"location":        user.Location,
+		"provider":        user.Provider,
+		"follower_count":  user.FollowerCount,
+		"following_count": user.FollowingCount,
+	}
+	for field := range data {
+		if _, ok := projection[field]; !ok {
+			delete(data, field)
+		}
+	}
+	return data
+}
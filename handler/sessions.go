@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"html/template"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"Backend-Auth-Profiles/utils"
+)
+
+const sessionCacheTTL = 30 * time.Second
+
+// SessionRevocation stores the last time all sessions of a user were revoked
+type SessionRevocation struct {
+	UserID    string    `bson:"user_id"`
+	RevokedAt time.Time `bson:"revoked_at"`
+}
+
+type cachedRevocation struct {
+	revokedAt time.Time
+	fetchedAt time.Time
+}
+
+// MongoSessionStore implements utils.SessionStore on top of the session_revocations collection
+type MongoSessionStore struct {
+	collection *mongo.Collection
+	mu         sync.Mutex
+	cache      map[string]cachedRevocation
+}
+
+func NewMongoSessionStore(client *mongo.Client) *MongoSessionStore {
+	return &MongoSessionStore{
+		collection: client.Database("authdb").Collection("session_revocations"),
+		cache:      map[string]cachedRevocation{},
+	}
+}
+
+func (s *MongoSessionStore) RevokedAt(userID string) (time.Time, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[userID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < sessionCacheTTL {
+		return cached.revokedAt, nil
+	}
+
+	var revocation SessionRevocation
+	err := s.collection.FindOne(context.Background(), bson.M{"user_id": userID}).Decode(&revocation)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return time.Time{}, err
+	}
+
+	s.mu.Lock()
+	s.cache[userID] = cachedRevocation{revokedAt: revocation.RevokedAt, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return revocation.RevokedAt, nil
+}
+
+func (s *MongoSessionStore) RevokeAll(userID string) error {
+	now := time.Now()
+	_, err := s.collection.UpdateOne(
+		context.Background(),
+		bson.M{"user_id": userID},
+		bson.M{"$set": bson.M{"revoked_at": now}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.cache[userID] = cachedRevocation{revokedAt: now, fetchedAt: now}
+	s.mu.Unlock()
+	return nil
+}
+
+// revokePage is served to browsers following the "this wasn't me" link. Signing out only happens
+// when the form is submitted, so link scanners and previews that fetch the URL change nothing.
+var revokePage = template.Must(template.New("revoke").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Sign out everywhere</title></head>
+<body>
+<p>{{.Message}}</p>
+{{if .Token}}<form method="POST" action="/auth/revoke">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Sign out of all sessions</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+func writeRevokePage(w http.ResponseWriter, message, token string, code int) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(code)
+	if err := revokePage.Execute(w, map[string]string{"Message": message, "Token": token}); err != nil {
+		log.Printf("Error rendering revoke page: %v", err)
+	}
+}
+
+// RevokeSessionsConfirmHandler handles GET /auth/revoke, the page linked from login alerts
+func RevokeSessionsConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		writeRevokePage(w, "This link is missing its token.", "", http.StatusBadRequest)
+		return
+	}
+	if _, err := utils.ParseRevokeToken(token); err != nil {
+		writeRevokePage(w, "This link is invalid or has expired.", "", http.StatusUnauthorized)
+		return
+	}
+	writeRevokePage(w, "If you did not sign in recently, sign out of every session on your account. You will need to sign in again on your own devices.", token, http.StatusOK)
+}
+
+// RevokeSessionsHandler handles POST /auth/revoke, submitted from the confirmation page
+func RevokeSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.PostFormValue("token")
+	if token == "" {
+		writeRevokePage(w, "This link is missing its token.", "", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.ParseRevokeToken(token)
+	if err != nil {
+		writeRevokePage(w, "This link is invalid or has expired.", "", http.StatusUnauthorized)
+		return
+	}
+
+	if err := utils.RevokeAllSessions(userID); err != nil {
+		writeRevokePage(w, "We could not sign you out. Please try again.", token, http.StatusInternalServerError)
+		return
+	}
+
+	writeRevokePage(w, "All sessions have been signed out.", "", http.StatusOK)
+}
@@ -3,10 +3,10 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 	"os"
 	"log"
@@ -14,6 +14,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"google.golang.org/api/idtoken"
 
 	"Backend-Auth-Profiles/utils"
@@ -23,9 +24,23 @@ import model "Backend-Auth-Profiles/models"
 
 // SocialAuthRequest defines the request structure for social login
 type SocialAuthRequest struct {
+	AuthToken  string `json:"auth_token"`            // Required
+	DeviceID   string `json:"device_id,omitempty"`   // Optional
+	FCMToken   string `json:"fcm_token,omitempty"`   // Optional
+	CookieMode bool   `json:"cookie_mode,omitempty"` // Optional, for web clients
+	ClientID   string `json:"client_id"`             // Required, audience of the issued tokens
+
+	// Consent given at signup; versions must match the published documents
+	TermsVersion   string `json:"terms_version,omitempty"`
+	PrivacyVersion string `json:"privacy_version,omitempty"`
+	MarketingEmail bool   `json:"marketing_email,omitempty"`
+	MarketingPush  bool   `json:"marketing_push,omitempty"`
+}
+
+// ReauthRequest defines the request structure for step-up re-authentication
+type ReauthRequest struct {
+	Provider  string `json:"provider"`   // google or facebook
 	AuthToken string `json:"auth_token"` // Required
-	DeviceID  string `json:"device_id,omitempty"` // Optional
-	FCMToken  string `json:"fcm_token,omitempty"` // Optional
 }
 
 // RefreshTokenRequest defines the request structure for refresh token
@@ -47,27 +62,118 @@ func FacebookLoginHandler(client *mongo.Client) http.HandlerFunc {
 
 func RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
 	var req RefreshTokenRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
+	// Web clients in cookie mode send only the refresh cookie
+	fromCookie := false
+	if req.RefreshToken == "" {
+		if cookie, err := r.Cookie(utils.RefreshTokenCookie); err == nil {
+			req.RefreshToken = cookie.Value
+			fromCookie = true
+		}
+	}
+
 	if req.RefreshToken == "" {
 		http.Error(w, "refresh_token is required", http.StatusBadRequest)
 		return
 	}
 
+	if fromCookie && !utils.ValidCSRF(r) {
+		http.Error(w, "Missing or invalid CSRF token", http.StatusForbidden)
+		return
+	}
+
 	accessToken, err := utils.RefreshAccessToken(req.RefreshToken)
+	if errors.Is(err, utils.ErrAuthUnavailable) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
+	if fromCookie {
+		utils.SetAccessCookie(w, accessToken)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": "Token refreshed",
+		})
+		return
+	}
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"access_token": accessToken,
 	})
 }
 
+// ReauthHandler issues a short-lived elevated token after the caller signs in with their provider again
+func ReauthHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req ReauthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AuthToken == "" {
+		http.Error(w, "auth_token is required", http.StatusBadRequest)
+		return
+	}
+
+	var userData map[string]interface{}
+	var err error
+
+	if req.Provider == "google" {
+		userData, err = validateGoogleToken(req.AuthToken)
+	} else if req.Provider == "facebook" {
+		userData, err = validateFacebookToken(req.AuthToken)
+	} else {
+		http.Error(w, "provider must be google or facebook", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if userData["id"].(string) != userID {
+		http.Error(w, "Provider account does not match the signed in user", http.StatusForbidden)
+		return
+	}
+
+	audience, _ := r.Context().Value("audience").(string)
+	elevatedToken, err := utils.GenerateElevatedToken(userID, audience)
+	if err != nil {
+		http.Error(w, "Token generation failed", http.StatusInternalServerError)
+		return
+	}
+
+	// Web clients in cookie mode never see tokens; the elevated one rides in its own cookie
+	if utils.UsesCookieAuth(r) {
+		utils.SetElevatedCookie(w, elevatedToken)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":    "Re-authentication successful",
+			"expires_in": int(utils.ElevatedTokenTTL.Seconds()),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":      "Re-authentication successful",
+		"access_token": elevatedToken,
+		"expires_in":   int(utils.ElevatedTokenTTL.Seconds()),
+	})
+}
+
 func handleSocialLogin(w http.ResponseWriter, r *http.Request, client *mongo.Client, provider string) {
 	ctx := context.Background()
 	var req SocialAuthRequest
@@ -82,8 +188,13 @@ func handleSocialLogin(w http.ResponseWriter, r *http.Request, client *mongo.Cli
 		return
 	}
 
+	audience, err := utils.ResolveAudience(req.ClientID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var userData map[string]interface{}
-	var err error
 
 	if provider == "google" {
 		userData, err = validateGoogleToken(req.AuthToken)
@@ -101,7 +212,10 @@ func handleSocialLogin(w http.ResponseWriter, r *http.Request, client *mongo.Cli
 	name := userData["name"].(string)
 	picture := userData["picture"].(string)
 
-	collection := client.Database("authdb").Collection("profile")
+	collection := profilesCollection(client)
+	ip := clientIP(r)
+	userAgent := r.UserAgent()
+	var newDevice, anomalous bool
 
 	var user model.User
 	err = collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&user)
@@ -109,13 +223,19 @@ func handleSocialLogin(w http.ResponseWriter, r *http.Request, client *mongo.Cli
 		language := "en"
 		areaOfInterest := map[string]map[string][]string{}
 		profileOfInterest := []string{}
-		username := generateUsername(name)
+		username, err := generateChannelName(ctx, client, name)
+		if err != nil {
+			http.Error(w, "User creation failed", http.StatusInternalServerError)
+			return
+		}
 
 		user = model.User{
 			UserID:            userID,
 			Email:             email,
 			Name:              name,
 			ChannelName:       username,
+			ChannelNameNorm:   username,
+			NameNorm:          normalizePrefix(name),
 			DeviceIDList:      []string{},
 			AreaOfExpert:      []string{},
 			AreaOfInterest:    areaOfInterest,
@@ -123,8 +243,6 @@ func handleSocialLogin(w http.ResponseWriter, r *http.Request, client *mongo.Cli
 			Language:          language,
 			WebAddress:        "",
 			Location:          "",
-			Follower:          []string{},
-			Following:         []string{},
 			Verified:          false,
 			ProfilePicture:    picture,
 			ProfileOfInterest: profileOfInterest,
@@ -134,6 +252,10 @@ func handleSocialLogin(w http.ResponseWriter, r *http.Request, client *mongo.Cli
 			RoomsCreated:      0,
 			Live:              false,
 			Provider:          provider,
+			LastLoginIP:       ip,
+			LastUserAgent:     userAgent,
+			Consent:           newUserConsent(ctx, client, req),
+			Onboarding:        &model.Onboarding{CompletedSteps: []string{}, SkippedSteps: []string{}},
 		}
 
 		// Only add device_id if provided
@@ -141,24 +263,43 @@ func handleSocialLogin(w http.ResponseWriter, r *http.Request, client *mongo.Cli
 			user.DeviceIDList = []string{req.DeviceID}
 		}
 
+		// The unique channel_name index catches races with concurrent signups; pick another name and retry
 		res, err := collection.InsertOne(ctx, user)
+		for attempt := 1; err != nil && mongo.IsDuplicateKeyError(err) && attempt < channelNameMaxAttempts; attempt++ {
+			if user.ChannelName, err = generateChannelName(ctx, client, name); err != nil {
+				break
+			}
+			user.ChannelNameNorm = user.ChannelName
+			res, err = collection.InsertOne(ctx, user)
+		}
 		if err != nil {
 			http.Error(w, "User creation failed", http.StatusInternalServerError)
 			return
 		}
 		user.ID = res.InsertedID.(primitive.ObjectID)
 	} else if err == nil {
+		if user.Suspension.Active() {
+			http.Error(w, "Account "+user.Suspension.Type+": "+user.Suspension.Reason, http.StatusForbidden)
+			return
+		}
+
+		newDevice, anomalous = detectLoginAnomaly(user, req.DeviceID, ip, userAgent)
 		update := bson.M{
 			"$set": bson.M{
-				"fcm_token":   req.FCMToken,
-				"updated_at": time.Now(),
+				"fcm_token":       req.FCMToken,
+				"last_login_ip":   ip,
+				"last_user_agent": userAgent,
+				"updated_at":      time.Now(),
 			},
 		}
 		// Only update device_id_list if device_id is provided
 		if req.DeviceID != "" {
 			update["$addToSet"] = bson.M{"device_id_list": req.DeviceID}
 		}
-		_, err := collection.UpdateOne(ctx, bson.M{"user_id": userID}, update)
+		// Respond with the stored document so its updated_at is the one PATCH /profile expects back
+		err := collection.FindOneAndUpdate(ctx, bson.M{"user_id": userID}, update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&user)
 		if err != nil {
 			http.Error(w, "User update failed", http.StatusInternalServerError)
 			return
@@ -168,17 +309,44 @@ func handleSocialLogin(w http.ResponseWriter, r *http.Request, client *mongo.Cli
 		return
 	}
 
-	accessToken, refreshToken, err := utils.GenerateTokens(user.UserID)
+	accessToken, refreshToken, err := utils.GenerateTokens(user.UserID, audience)
 	if err != nil {
 		http.Error(w, "Token generation failed", http.StatusInternalServerError)
 		return
 	}
 
+	go recordAuthActivity(client, user, AuthActivity{
+		UserID:    user.UserID,
+		Provider:  provider,
+		DeviceID:  req.DeviceID,
+		IP:        ip,
+		UserAgent: userAgent,
+		NewDevice: newDevice,
+		Anomalous: anomalous,
+		CreatedAt: time.Now(),
+	})
+
+	if req.CookieMode {
+		csrfToken, err := utils.SetAuthCookies(w, accessToken, refreshToken)
+		if err != nil {
+			http.Error(w, "Token generation failed", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":             "Login successful",
+			"csrf_token":          csrfToken,
+			"user":                user,
+			"onboarding_complete": user.Onboarding.Complete(),
+		})
+		return
+	}
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message":       "Login successful",
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-		"user":          user,
+		"message":             "Login successful",
+		"access_token":        accessToken,
+		"refresh_token":       refreshToken,
+		"user":                user,
+		"onboarding_complete": user.Onboarding.Complete(),
 	})
 }
 
@@ -245,8 +413,4 @@ func validateFacebookToken(token string) (map[string]interface{}, error) {
 		"name":    result["name"].(string),
 		"picture": result["picture"].(map[string]interface{})["data"].(map[string]interface{})["url"].(string),
 	}, nil
-}
-
-func generateUsername(name string) string {
-	return strings.ToLower(strings.ReplaceAll(name, " ", "")) + fmt.Sprintf("%d", time.Now().Unix()%1000)
 }
\ No newline at end of file
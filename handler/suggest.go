@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+)
+
+const (
+	defaultSuggestLimit = 8
+	maxSuggestLimit     = 20
+	// minSuggestPrefix keeps one-letter prefixes from scanning a large part of the indexes
+	minSuggestPrefix = 2
+)
+
+// suggestProfileFields adds what the suggestion ranking needs to miniProfileFields
+var suggestProfileFields = bson.M{"_id": 1, "channel_name": 1, "name": 1, "profile_picture": 1, "verified": 1, "live": 1}
+
+// ProfileSuggestion is the compact profile returned by autocomplete
+type ProfileSuggestion struct {
+	ID             string `json:"_id"`
+	ChannelName    string `json:"channel_name"`
+	Name           string `json:"name"`
+	ProfilePicture string `json:"profile_picture"`
+	Verified       bool   `json:"verified"`
+}
+
+// normalizePrefix lowercases and collapses whitespace so prefixes match the *_norm fields
+func normalizePrefix(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// EnsureSuggestIndexes creates the prefix indexes and backfills the normalized fields for older profiles
+func EnsureSuggestIndexes(client *mongo.Client) {
+	ctx := context.Background()
+	collection := profilesCollection(client)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "channel_name_norm", Value: 1}}, Options: options.Index().SetName("channel_name_prefix")},
+		{Keys: bson.D{{Key: "name_norm", Value: 1}}, Options: options.Index().SetName("name_prefix")},
+	})
+	if err != nil {
+		log.Printf("Error creating suggest indexes: %v", err)
+	}
+
+	// Normalized in Go, since $toLower only lowercases ASCII and queries use normalizePrefix
+	cursor, err := collection.Find(ctx,
+		bson.M{"$or": bson.A{bson.M{"channel_name_norm": bson.M{"$exists": false}}, bson.M{"name_norm": bson.M{"$exists": false}}}},
+		options.Find().SetProjection(bson.M{"channel_name": 1, "name": 1}),
+	)
+	if err != nil {
+		log.Printf("Error backfilling normalized names: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var user model.User
+		if err := cursor.Decode(&user); err != nil {
+			log.Printf("Error backfilling normalized names: %v", err)
+			continue
+		}
+		_, err := collection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{
+			"channel_name_norm": strings.ToLower(user.ChannelName),
+			"name_norm":         normalizePrefix(user.Name),
+		}})
+		if err != nil {
+			log.Printf("Error backfilling normalized names of %s: %v", user.ID.Hex(), err)
+		}
+	}
+}
+
+// SuggestProfilesHandler handles GET /search/suggest?q=
+func SuggestProfilesHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prefix := normalizePrefix(strings.TrimPrefix(r.URL.Query().Get("q"), "@"))
+		if prefix == "" {
+			writeJSONError(w, "Missing q parameter", http.StatusBadRequest)
+			return
+		}
+		if utf8.RuneCountInString(prefix) < minSuggestPrefix {
+			writeJSONError(w, "q must be at least 2 characters", http.StatusBadRequest)
+			return
+		}
+		limit := parseLimit(r, defaultSuggestLimit, maxSuggestLimit)
+
+		ctx := context.Background()
+		filter := bson.M{"$or": bson.A{
+			bson.M{"suspension": bson.M{"$exists": false}},
+			bson.M{"suspension.expires_at": bson.M{"$lte": time.Now()}},
+		}}
+		blocked, err := callerBlockedIDs(ctx, r, client)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if len(blocked) > 0 {
+			filter["_id"] = bson.M{"$nin": blocked}
+		}
+
+		// Each field is scanned on its own prefix index, in index order, so no query sorts in
+		// memory; live and verified profiles are then ranked first within those few matches
+		pattern := "^" + regexp.QuoteMeta(prefix)
+		seen := map[primitive.ObjectID]bool{}
+		var users []model.User
+		for _, field := range []string{"channel_name_norm", "name_norm"} {
+			query := bson.M{field: bson.M{"$regex": pattern}}
+			for key, value := range filter {
+				query[key] = value
+			}
+			cursor, err := profilesCollection(client).Find(ctx, query, options.Find().
+				SetSort(bson.D{{Key: field, Value: 1}}).
+				SetLimit(int64(limit)).
+				SetProjection(suggestProfileFields))
+			if err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			var matches []model.User
+			err = cursor.All(ctx, &matches)
+			cursor.Close(ctx)
+			if err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			for _, user := range matches {
+				if !seen[user.ID] {
+					seen[user.ID] = true
+					users = append(users, user)
+				}
+			}
+		}
+		sort.SliceStable(users, func(i, j int) bool {
+			if users[i].Live != users[j].Live {
+				return users[i].Live
+			}
+			return users[i].Verified && !users[j].Verified
+		})
+		if len(users) > limit {
+			users = users[:limit]
+		}
+
+		suggestions := []ProfileSuggestion{}
+		for _, user := range users {
+			suggestions = append(suggestions, ProfileSuggestion{
+				ID:             user.ID.Hex(),
+				ChannelName:    user.ChannelName,
+				Name:           user.Name,
+				ProfilePicture: user.ProfilePicture,
+				Verified:       user.Verified,
+			})
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"profiles": suggestions},
+			Message: "Suggestions",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
@@ -0,0 +1,766 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	taxonomyCacheTTL       = 5 * time.Minute
+	maxExpertiseEntries    = 10
+	maxInterestSubcategory = 20
+)
+
+// Taxonomy levels, from the root down
+const (
+	TaxonomyBranch      = "branch"
+	TaxonomyCategory    = "category"
+	TaxonomySubcategory = "subcategory"
+)
+
+var taxonomySlugPattern = regexp.MustCompile(`^[a-z0-9-]{2,50}$`)
+
+// TaxonomyNode is one entry of the interest taxonomy. Slugs are unique across all levels
+// and are what profiles store in area_of_interest and area_of_expert.
+type TaxonomyNode struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Slug       string             `bson:"slug" json:"slug"`
+	Name       string             `bson:"name" json:"name"`
+	Level      string             `bson:"level" json:"level"`
+	ParentSlug string             `bson:"parent_slug,omitempty" json:"parent_slug,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// TaxonomyNodeCounts is how many users picked a node as an interest or as their expertise
+type TaxonomyNodeCounts struct {
+	Interested int64 `json:"interested_count"`
+	Experts    int64 `json:"expert_count"`
+}
+
+// TaxonomyTreeNode is a node with its counts and children, as served by GET /taxonomy
+type TaxonomyTreeNode struct {
+	TaxonomyNode
+	TaxonomyNodeCounts
+	Children []*TaxonomyTreeNode `json:"children,omitempty"`
+}
+
+// CreateTaxonomyNodeRequest defines the request structure for POST /admin/taxonomy
+type CreateTaxonomyNodeRequest struct {
+	Slug       string `json:"slug"`
+	Name       string `json:"name"`
+	Level      string `json:"level"`
+	ParentSlug string `json:"parent_slug,omitempty"` // Required below the branch level
+}
+
+// UpdateTaxonomyNodeRequest defines the request structure for PATCH /admin/taxonomy/{slug}
+type UpdateTaxonomyNodeRequest struct {
+	Name string `json:"name"`
+}
+
+// MongoTaxonomyStore serves the taxonomy and its user counts from a short-lived cache
+type MongoTaxonomyStore struct {
+	client        *mongo.Client
+	mu            sync.Mutex
+	nodes         map[string]TaxonomyNode
+	nodesFetched  time.Time
+	counts        map[string]TaxonomyNodeCounts
+	countsFetched time.Time
+}
+
+func NewMongoTaxonomyStore(client *mongo.Client) *MongoTaxonomyStore {
+	return &MongoTaxonomyStore{client: client}
+}
+
+func taxonomyCollection(client *mongo.Client) *mongo.Collection {
+	return client.Database("authdb").Collection("taxonomy")
+}
+
+// EnsureTaxonomyIndexes creates the unique slug index
+func EnsureTaxonomyIndexes(client *mongo.Client) {
+	_, err := taxonomyCollection(client).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "slug", Value: 1}},
+		Options: options.Index().SetName("slug_unique").SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("Error creating taxonomy index: %v", err)
+	}
+}
+
+// Nodes returns every taxonomy node keyed by slug
+func (s *MongoTaxonomyStore) Nodes(ctx context.Context) (map[string]TaxonomyNode, error) {
+	s.mu.Lock()
+	if s.nodes != nil && time.Since(s.nodesFetched) < taxonomyCacheTTL {
+		nodes := s.nodes
+		s.mu.Unlock()
+		return nodes, nil
+	}
+	s.mu.Unlock()
+
+	cursor, err := taxonomyCollection(s.client).Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var list []TaxonomyNode
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	nodes := map[string]TaxonomyNode{}
+	for _, node := range list {
+		nodes[node.Slug] = node
+	}
+
+	s.mu.Lock()
+	s.nodes = nodes
+	s.nodesFetched = time.Now()
+	s.mu.Unlock()
+	return nodes, nil
+}
+
+// Counts returns how many users reference each slug as an interest or as expertise
+func (s *MongoTaxonomyStore) Counts(ctx context.Context) (map[string]TaxonomyNodeCounts, error) {
+	s.mu.Lock()
+	if s.counts != nil && time.Since(s.countsFetched) < taxonomyCacheTTL {
+		counts := s.counts
+		s.mu.Unlock()
+		return counts, nil
+	}
+	s.mu.Unlock()
+
+	profiles := profilesCollection(s.client)
+	counts := map[string]TaxonomyNodeCounts{}
+
+	// Each unwind level yields one document per user and node, so grouping by slug counts users
+	cursor, err := profiles.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"area_of_interest": bson.M{"$type": "object"}}}},
+		{{Key: "$project", Value: bson.M{"branch": bson.M{"$objectToArray": "$area_of_interest"}}}},
+		{{Key: "$unwind", Value: "$branch"}},
+		{{Key: "$project", Value: bson.M{"slug": "$branch.k", "categories": bson.M{"$objectToArray": "$branch.v"}}}},
+		{{Key: "$facet", Value: bson.M{
+			"branches":   bson.A{bson.M{"$group": bson.M{"_id": "$slug", "count": bson.M{"$sum": 1}}}},
+			"categories": bson.A{bson.M{"$unwind": "$categories"}, bson.M{"$group": bson.M{"_id": "$categories.k", "count": bson.M{"$sum": 1}}}},
+			"subcategories": bson.A{
+				bson.M{"$unwind": "$categories"},
+				bson.M{"$unwind": "$categories.v"},
+				bson.M{"$group": bson.M{"_id": "$categories.v", "count": bson.M{"$sum": 1}}},
+			},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var interests []map[string][]struct {
+		Slug  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &interests); err != nil {
+		return nil, err
+	}
+	for _, facet := range interests {
+		for _, groups := range facet {
+			for _, group := range groups {
+				c := counts[group.Slug]
+				c.Interested += group.Count
+				counts[group.Slug] = c
+			}
+		}
+	}
+
+	cursor, err = profiles.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$unwind", Value: "$area_of_expert"}},
+		{{Key: "$group", Value: bson.M{"_id": "$area_of_expert", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var experts []struct {
+		Slug  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &experts); err != nil {
+		return nil, err
+	}
+	for _, group := range experts {
+		c := counts[group.Slug]
+		c.Experts = group.Count
+		counts[group.Slug] = c
+	}
+
+	s.mu.Lock()
+	s.counts = counts
+	s.countsFetched = time.Now()
+	s.mu.Unlock()
+	return counts, nil
+}
+
+// Invalidate drops the cached nodes and counts so the next read sees admin changes
+func (s *MongoTaxonomyStore) Invalidate() {
+	s.mu.Lock()
+	s.nodes = nil
+	s.counts = nil
+	s.mu.Unlock()
+}
+
+// taxonomyNodesBySlug reads the given nodes straight from the collection. The store cache only
+// serves reads; writes are checked here because another instance may have changed the taxonomy.
+func taxonomyNodesBySlug(ctx context.Context, client *mongo.Client, slugs []string) (map[string]TaxonomyNode, error) {
+	nodes := map[string]TaxonomyNode{}
+	if len(slugs) == 0 {
+		return nodes, nil
+	}
+	cursor, err := taxonomyCollection(client).Find(ctx, bson.M{"slug": bson.M{"$in": slugs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var list []TaxonomyNode
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	for _, node := range list {
+		nodes[node.Slug] = node
+	}
+	return nodes, nil
+}
+
+// validateInterests checks an area_of_interest tree against the taxonomy and drops repeated
+// subcategories in place, so counts and the subcategory limit see each slug once
+func validateInterests(nodes map[string]TaxonomyNode, interests map[string]map[string][]string) string {
+	for branch, categories := range interests {
+		if node, ok := nodes[branch]; !ok || node.Level != TaxonomyBranch {
+			return "unknown branch " + branch
+		}
+		for category, subcategories := range categories {
+			if node, ok := nodes[category]; !ok || node.Level != TaxonomyCategory || node.ParentSlug != branch {
+				return "unknown category " + category + " in " + branch
+			}
+			seen := map[string]bool{}
+			unique := []string{}
+			for _, subcategory := range subcategories {
+				if node, ok := nodes[subcategory]; !ok || node.Level != TaxonomySubcategory || node.ParentSlug != category {
+					return "unknown subcategory " + subcategory + " in " + category
+				}
+				if !seen[subcategory] {
+					seen[subcategory] = true
+					unique = append(unique, subcategory)
+				}
+			}
+			if len(unique) > maxInterestSubcategory {
+				return "too many subcategories in " + category
+			}
+			categories[category] = unique
+		}
+	}
+	return ""
+}
+
+// validateExpertise checks an area_of_expert list against the taxonomy and returns it without repeats
+func validateExpertise(nodes map[string]TaxonomyNode, slugs []string) ([]string, string) {
+	seen := map[string]bool{}
+	expertise := []string{}
+	for _, slug := range slugs {
+		if _, ok := nodes[slug]; !ok {
+			return nil, "unknown expertise " + slug
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			expertise = append(expertise, slug)
+		}
+	}
+	return expertise, ""
+}
+
+// cleanInterests rebuilds a stored area_of_interest value from the parts that match the taxonomy.
+// It reports whether anything was dropped: unknown or misplaced slugs, repeats, values of the
+// wrong type and subcategories past the limit.
+func cleanInterests(nodes map[string]TaxonomyNode, raw bson.RawValue) (map[string]map[string][]string, bool) {
+	cleaned := map[string]map[string][]string{}
+	doc, ok := raw.DocumentOK()
+	if !ok {
+		return cleaned, true
+	}
+	branches, err := doc.Elements()
+	if err != nil {
+		return cleaned, true
+	}
+
+	changed := false
+	for _, b := range branches {
+		branch := b.Key()
+		categories, ok := b.Value().DocumentOK()
+		if node, known := nodes[branch]; !ok || !known || node.Level != TaxonomyBranch {
+			changed = true
+			continue
+		}
+		elements, err := categories.Elements()
+		if err != nil {
+			changed = true
+			continue
+		}
+		cleaned[branch] = map[string][]string{}
+		for _, c := range elements {
+			category := c.Key()
+			subcategories, ok := c.Value().ArrayOK()
+			if node, known := nodes[category]; !ok || !known || node.Level != TaxonomyCategory || node.ParentSlug != branch {
+				changed = true
+				continue
+			}
+			values, err := subcategories.Values()
+			if err != nil {
+				changed = true
+				continue
+			}
+			seen := map[string]bool{}
+			kept := []string{}
+			for _, v := range values {
+				subcategory, ok := v.StringValueOK()
+				node, known := nodes[subcategory]
+				if !ok || !known || node.Level != TaxonomySubcategory || node.ParentSlug != category ||
+					seen[subcategory] || len(kept) == maxInterestSubcategory {
+					changed = true
+					continue
+				}
+				seen[subcategory] = true
+				kept = append(kept, subcategory)
+			}
+			cleaned[branch][category] = kept
+		}
+	}
+	return cleaned, changed
+}
+
+// cleanExpertise rebuilds a stored area_of_expert value from the known slugs, without repeats and
+// up to the limit. Anything that is not an array, such as legacy free-form text, becomes empty.
+func cleanExpertise(nodes map[string]TaxonomyNode, raw bson.RawValue) ([]string, bool) {
+	cleaned := []string{}
+	array, ok := raw.ArrayOK()
+	if !ok {
+		return cleaned, true
+	}
+	values, err := array.Values()
+	if err != nil {
+		return cleaned, true
+	}
+
+	changed := false
+	seen := map[string]bool{}
+	for _, v := range values {
+		slug, ok := v.StringValueOK()
+		if _, known := nodes[slug]; !ok || !known || seen[slug] || len(cleaned) == maxExpertiseEntries {
+			changed = true
+			continue
+		}
+		seen[slug] = true
+		cleaned = append(cleaned, slug)
+	}
+	return cleaned, changed
+}
+
+// CleanInterests validates every stored area_of_interest and area_of_expert against the taxonomy
+// and rewrites the ones that do not match, including free-form values saved before the taxonomy existed
+func CleanInterests(client *mongo.Client) error {
+	ctx := context.Background()
+	nodes, err := NewMongoTaxonomyStore(client).Nodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	profiles := profilesCollection(client)
+	cursor, err := profiles.Find(ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"area_of_interest": bson.M{"$exists": true, "$ne": nil}},
+			bson.M{"area_of_expert": bson.M{"$exists": true, "$ne": nil}},
+		}},
+		options.Find().SetProjection(bson.M{"area_of_interest": 1, "area_of_expert": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	cleanedCount := 0
+	for cursor.Next(ctx) {
+		var profile struct {
+			ID             primitive.ObjectID `bson:"_id"`
+			AreaOfInterest bson.RawValue      `bson:"area_of_interest"`
+			AreaOfExpert   bson.RawValue      `bson:"area_of_expert"`
+		}
+		if err := cursor.Decode(&profile); err != nil {
+			return err
+		}
+		set := bson.M{}
+		if profile.AreaOfInterest.Type != 0 && profile.AreaOfInterest.Type != bson.TypeNull {
+			if interests, changed := cleanInterests(nodes, profile.AreaOfInterest); changed {
+				set["area_of_interest"] = interests
+			}
+		}
+		if profile.AreaOfExpert.Type != 0 && profile.AreaOfExpert.Type != bson.TypeNull {
+			if expertise, changed := cleanExpertise(nodes, profile.AreaOfExpert); changed {
+				set["area_of_expert"] = expertise
+			}
+		}
+		if len(set) == 0 {
+			continue
+		}
+		set["updated_at"] = time.Now()
+		if _, err := profiles.UpdateOne(ctx, bson.M{"_id": profile.ID}, bson.M{"$set": set}); err != nil {
+			return err
+		}
+		log.Printf("Cleaned interests and expertise of profile %s", profile.ID.Hex())
+		cleanedCount++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	log.Printf("Cleaned interests and expertise of %d profiles", cleanedCount)
+	return nil
+}
+
+// taxonomyNodeInUse asks the profiles directly instead of the cached counts, so a node picked
+// in the last few minutes is never deleted from under its users
+func taxonomyNodeInUse(ctx context.Context, client *mongo.Client, node TaxonomyNode) (bool, error) {
+	used := bson.A{bson.M{"area_of_expert": node.Slug}}
+	switch node.Level {
+	case TaxonomyBranch:
+		used = append(used, bson.M{"area_of_interest." + node.Slug: bson.M{"$exists": true}})
+	case TaxonomyCategory:
+		used = append(used, bson.M{"area_of_interest." + node.ParentSlug + "." + node.Slug: bson.M{"$exists": true}})
+	case TaxonomySubcategory:
+		var category TaxonomyNode
+		err := taxonomyCollection(client).FindOne(ctx, bson.M{"slug": node.ParentSlug}).Decode(&category)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return false, err
+		}
+		if err == nil {
+			used = append(used, bson.M{"area_of_interest." + category.ParentSlug + "." + node.ParentSlug: node.Slug})
+		}
+	}
+	count, err := profilesCollection(client).CountDocuments(ctx, bson.M{"$or": used}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// TaxonomyHandler handles GET /taxonomy
+func TaxonomyHandler(store *MongoTaxonomyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.Background()
+		nodes, err := store.Nodes(ctx)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		counts, err := store.Counts(ctx)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		tree := map[string]*TaxonomyTreeNode{}
+		for slug, node := range nodes {
+			tree[slug] = &TaxonomyTreeNode{TaxonomyNode: node, TaxonomyNodeCounts: counts[slug]}
+		}
+		roots := []*TaxonomyTreeNode{}
+		for _, node := range sortedTaxonomyNodes(tree) {
+			if parent, ok := tree[node.ParentSlug]; ok && node.Level != TaxonomyBranch {
+				parent.Children = append(parent.Children, node)
+			} else if node.Level == TaxonomyBranch {
+				roots = append(roots, node)
+			}
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"branches": roots},
+			Message: "Taxonomy",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// sortedTaxonomyNodes orders nodes by name so children come out alphabetically
+func sortedTaxonomyNodes(tree map[string]*TaxonomyTreeNode) []*TaxonomyTreeNode {
+	list := make([]*TaxonomyTreeNode, 0, len(tree))
+	for _, node := range tree {
+		list = append(list, node)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
+
+// CreateTaxonomyNodeHandler handles POST /admin/taxonomy
+func CreateTaxonomyNodeHandler(client *mongo.Client, store *MongoTaxonomyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CreateTaxonomyNodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		if !taxonomySlugPattern.MatchString(req.Slug) {
+			writeJSONError(w, "slug must be 2-50 characters of lowercase letters, digits or dashes", http.StatusBadRequest)
+			return
+		}
+		if req.Name == "" {
+			writeJSONError(w, "name is required", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.Background()
+		var parentLevel string
+		switch req.Level {
+		case TaxonomyBranch:
+			req.ParentSlug = ""
+		case TaxonomyCategory:
+			parentLevel = TaxonomyBranch
+		case TaxonomySubcategory:
+			parentLevel = TaxonomyCategory
+		default:
+			writeJSONError(w, "level must be branch, category or subcategory", http.StatusBadRequest)
+			return
+		}
+		if parentLevel != "" {
+			nodes, err := taxonomyNodesBySlug(ctx, client, []string{req.ParentSlug})
+			if err != nil {
+				writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if parent, ok := nodes[req.ParentSlug]; !ok || parent.Level != parentLevel {
+				writeJSONError(w, "parent_slug must be an existing "+parentLevel, http.StatusBadRequest)
+				return
+			}
+		}
+
+		now := time.Now()
+		node := TaxonomyNode{
+			Slug:       req.Slug,
+			Name:       req.Name,
+			Level:      req.Level,
+			ParentSlug: req.ParentSlug,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		result, err := taxonomyCollection(client).InsertOne(ctx, node)
+		if mongo.IsDuplicateKeyError(err) {
+			writeJSONError(w, "slug already exists", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Failed to create taxonomy node", http.StatusInternalServerError)
+			return
+		}
+		node.ID = result.InsertedID.(primitive.ObjectID)
+		store.Invalidate()
+
+		response := Response{
+			Data:    node,
+			Message: "Taxonomy node created",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusCreated)
+	}
+}
+
+// UpdateTaxonomyNodeHandler handles PATCH /admin/taxonomy/{slug}; slugs are stable, only names change
+func UpdateTaxonomyNodeHandler(client *mongo.Client, store *MongoTaxonomyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req UpdateTaxonomyNodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" {
+			writeJSONError(w, "name is required", http.StatusBadRequest)
+			return
+		}
+
+		var node TaxonomyNode
+		err := taxonomyCollection(client).FindOneAndUpdate(context.Background(),
+			bson.M{"slug": mux.Vars(r)["slug"]},
+			bson.M{"$set": bson.M{"name": req.Name, "updated_at": time.Now()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&node)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "Taxonomy node not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Failed to update taxonomy node", http.StatusInternalServerError)
+			return
+		}
+		store.Invalidate()
+
+		response := Response{
+			Data:    node,
+			Message: "Taxonomy node updated",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// DeleteTaxonomyNodeHandler handles DELETE /admin/taxonomy/{slug}. Nodes with children or users cannot be deleted.
+func DeleteTaxonomyNodeHandler(client *mongo.Client, store *MongoTaxonomyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		slug := mux.Vars(r)["slug"]
+		ctx := context.Background()
+
+		var node TaxonomyNode
+		err := taxonomyCollection(client).FindOne(ctx, bson.M{"slug": slug}).Decode(&node)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "Taxonomy node not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		children, err := taxonomyCollection(client).CountDocuments(ctx, bson.M{"parent_slug": slug})
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if children > 0 {
+			writeJSONError(w, "Taxonomy node has children", http.StatusConflict)
+			return
+		}
+		inUse, err := taxonomyNodeInUse(ctx, client, node)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if inUse {
+			writeJSONError(w, "Taxonomy node is still used by profiles", http.StatusConflict)
+			return
+		}
+
+		result, err := taxonomyCollection(client).DeleteOne(ctx, bson.M{"slug": slug})
+		if err != nil {
+			writeJSONError(w, "Failed to delete taxonomy node", http.StatusInternalServerError)
+			return
+		}
+		if result.DeletedCount == 0 {
+			writeJSONError(w, "Taxonomy node not found", http.StatusNotFound)
+			return
+		}
+		store.Invalidate()
+
+		writeJSONResponse(w, Response{Message: "Taxonomy node deleted", Status: true}, http.StatusOK)
+	}
+}
+
+// UpdateInterestsHandler handles PUT /profile/interests with a branch -> category -> subcategories tree
+func UpdateInterestsHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req struct {
+			AreaOfInterest map[string]map[string][]string `json:"area_of_interest"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.AreaOfInterest == nil {
+			req.AreaOfInterest = map[string]map[string][]string{}
+		}
+
+		ctx := context.Background()
+		slugs := []string{}
+		for slug := range interestSlugs(req.AreaOfInterest) {
+			slugs = append(slugs, slug)
+		}
+		nodes, err := taxonomyNodesBySlug(ctx, client, slugs)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if msg := validateInterests(nodes, req.AreaOfInterest); msg != "" {
+			writeJSONError(w, msg, http.StatusUnprocessableEntity)
+			return
+		}
+
+		updateProfileTaxonomyField(w, client, userID, "area_of_interest", req.AreaOfInterest)
+	}
+}
+
+// UpdateExpertiseHandler handles PUT /profile/expertise with a list of taxonomy slugs
+func UpdateExpertiseHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req struct {
+			AreaOfExpert []string `json:"area_of_expert"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if len(req.AreaOfExpert) > maxExpertiseEntries {
+			writeJSONError(w, "area_of_expert must contain at most 10 entries", http.StatusUnprocessableEntity)
+			return
+		}
+
+		ctx := context.Background()
+		nodes, err := taxonomyNodesBySlug(ctx, client, req.AreaOfExpert)
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		expertise, msg := validateExpertise(nodes, req.AreaOfExpert)
+		if msg != "" {
+			writeJSONError(w, msg, http.StatusUnprocessableEntity)
+			return
+		}
+
+		updateProfileTaxonomyField(w, client, userID, "area_of_expert", expertise)
+	}
+}
+
+func updateProfileTaxonomyField(w http.ResponseWriter, client *mongo.Client, userID, field string, value interface{}) {
+	result, err := profilesCollection(client).UpdateOne(context.Background(),
+		bson.M{"user_id": userID},
+		bson.M{"$set": bson.M{field: value, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		writeJSONError(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		writeJSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	response := Response{
+		Data:    map[string]interface{}{field: value},
+		Message: "Profile updated",
+		Status:  true,
+	}
+	writeJSONResponse(w, response, http.StatusOK)
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func testTaxonomyNodes() map[string]TaxonomyNode {
+	return map[string]TaxonomyNode{
+		"tech":     {Slug: "tech", Level: TaxonomyBranch},
+		"arts":     {Slug: "arts", Level: TaxonomyBranch},
+		"software": {Slug: "software", Level: TaxonomyCategory, ParentSlug: "tech"},
+		"music":    {Slug: "music", Level: TaxonomyCategory, ParentSlug: "arts"},
+		"go":       {Slug: "go", Level: TaxonomySubcategory, ParentSlug: "software"},
+		"rust":     {Slug: "rust", Level: TaxonomySubcategory, ParentSlug: "software"},
+		"jazz":     {Slug: "jazz", Level: TaxonomySubcategory, ParentSlug: "music"},
+	}
+}
+
+// rawValue encodes v the way it would come back from a profile document
+func rawValue(t *testing.T, v interface{}) bson.RawValue {
+	t.Helper()
+	doc, err := bson.Marshal(bson.M{"v": v})
+	if err != nil {
+		t.Fatalf("marshal %v: %v", v, err)
+	}
+	return bson.Raw(doc).Lookup("v")
+}
+
+func manySubcategories(slug string, n int) []string {
+	list := make([]string, n)
+	for i := range list {
+		list[i] = slug
+	}
+	return list
+}
+
+func TestValidateInterests(t *testing.T) {
+	tests := []struct {
+		name      string
+		interests map[string]map[string][]string
+		wantMsg   string
+		want      map[string]map[string][]string
+	}{
+		{
+			name:      "empty",
+			interests: map[string]map[string][]string{},
+			want:      map[string]map[string][]string{},
+		},
+		{
+			name:      "valid tree",
+			interests: map[string]map[string][]string{"tech": {"software": {"go", "rust"}}},
+			want:      map[string]map[string][]string{"tech": {"software": {"go", "rust"}}},
+		},
+		{
+			name:      "repeats are dropped in place",
+			interests: map[string]map[string][]string{"tech": {"software": {"go", "go", "rust", "go"}}},
+			want:      map[string]map[string][]string{"tech": {"software": {"go", "rust"}}},
+		},
+		{
+			name:      "repeats do not count towards the limit",
+			interests: map[string]map[string][]string{"tech": {"software": manySubcategories("go", maxInterestSubcategory+1)}},
+			want:      map[string]map[string][]string{"tech": {"software": {"go"}}},
+		},
+		{
+			name:      "unknown branch",
+			interests: map[string]map[string][]string{"cooking": {}},
+			wantMsg:   "unknown branch cooking",
+		},
+		{
+			name:      "category is not a branch",
+			interests: map[string]map[string][]string{"software": {}},
+			wantMsg:   "unknown branch software",
+		},
+		{
+			name:      "category under the wrong branch",
+			interests: map[string]map[string][]string{"arts": {"software": {}}},
+			wantMsg:   "unknown category software in arts",
+		},
+		{
+			name:      "subcategory under the wrong category",
+			interests: map[string]map[string][]string{"tech": {"software": {"jazz"}}},
+			wantMsg:   "unknown subcategory jazz in software",
+		},
+		{
+			name:      "unknown subcategory",
+			interests: map[string]map[string][]string{"tech": {"software": {"cobol"}}},
+			wantMsg:   "unknown subcategory cobol in software",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := validateInterests(testTaxonomyNodes(), tt.interests)
+			if msg != tt.wantMsg {
+				t.Fatalf("validateInterests() = %q, want %q", msg, tt.wantMsg)
+			}
+			if tt.wantMsg == "" && !reflect.DeepEqual(tt.interests, tt.want) {
+				t.Errorf("interests = %v, want %v", tt.interests, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateExpertise(t *testing.T) {
+	tests := []struct {
+		name    string
+		slugs   []string
+		want    []string
+		wantMsg string
+	}{
+		{name: "empty", slugs: nil, want: []string{}},
+		{name: "any level", slugs: []string{"tech", "software", "go"}, want: []string{"tech", "software", "go"}},
+		{name: "repeats are dropped", slugs: []string{"go", "tech", "go"}, want: []string{"go", "tech"}},
+		{name: "unknown slug", slugs: []string{"go", "cobol"}, wantMsg: "unknown expertise cobol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, msg := validateExpertise(testTaxonomyNodes(), tt.slugs)
+			if msg != tt.wantMsg {
+				t.Fatalf("validateExpertise() message = %q, want %q", msg, tt.wantMsg)
+			}
+			if tt.wantMsg == "" && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateExpertise() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanInterests(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         interface{}
+		want        map[string]map[string][]string
+		wantChanged bool
+	}{
+		{
+			name: "valid tree is kept",
+			raw:  bson.M{"tech": bson.M{"software": bson.A{"go", "rust"}}},
+			want: map[string]map[string][]string{"tech": {"software": {"go", "rust"}}},
+		},
+		{
+			name:        "legacy free-form string",
+			raw:         "coding, music",
+			want:        map[string]map[string][]string{},
+			wantChanged: true,
+		},
+		{
+			name:        "legacy array",
+			raw:         bson.A{"coding", "music"},
+			want:        map[string]map[string][]string{},
+			wantChanged: true,
+		},
+		{
+			name:        "unknown branch is dropped",
+			raw:         bson.M{"cooking": bson.M{}, "tech": bson.M{"software": bson.A{"go"}}},
+			want:        map[string]map[string][]string{"tech": {"software": {"go"}}},
+			wantChanged: true,
+		},
+		{
+			name:        "branch that is not a document",
+			raw:         bson.M{"tech": "software"},
+			want:        map[string]map[string][]string{},
+			wantChanged: true,
+		},
+		{
+			name:        "category under the wrong branch",
+			raw:         bson.M{"arts": bson.M{"software": bson.A{"go"}, "music": bson.A{"jazz"}}},
+			want:        map[string]map[string][]string{"arts": {"music": {"jazz"}}},
+			wantChanged: true,
+		},
+		{
+			name:        "subcategories that are not an array",
+			raw:         bson.M{"tech": bson.M{"software": "go"}},
+			want:        map[string]map[string][]string{"tech": {}},
+			wantChanged: true,
+		},
+		{
+			name:        "unknown, misplaced, repeated and non-string subcategories",
+			raw:         bson.M{"tech": bson.M{"software": bson.A{"go", "jazz", "cobol", "go", 42, "rust"}}},
+			want:        map[string]map[string][]string{"tech": {"software": {"go", "rust"}}},
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := cleanInterests(testTaxonomyNodes(), rawValue(t, tt.raw))
+			if changed != tt.wantChanged {
+				t.Errorf("cleanInterests() changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cleanInterests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanInterestsSubcategoryLimit(t *testing.T) {
+	nodes := testTaxonomyNodes()
+	stored := bson.A{}
+	want := []string{}
+	for i := 0; i < maxInterestSubcategory+2; i++ {
+		slug := "sub-" + string(rune('a'+i))
+		nodes[slug] = TaxonomyNode{Slug: slug, Level: TaxonomySubcategory, ParentSlug: "software"}
+		stored = append(stored, slug)
+		if i < maxInterestSubcategory {
+			want = append(want, slug)
+		}
+	}
+
+	got, changed := cleanInterests(nodes, rawValue(t, bson.M{"tech": bson.M{"software": stored}}))
+	if !changed {
+		t.Error("cleanInterests() changed = false, want true")
+	}
+	if !reflect.DeepEqual(got["tech"]["software"], want) {
+		t.Errorf("cleanInterests() subcategories = %v, want %v", got["tech"]["software"], want)
+	}
+}
+
+func TestCleanExpertise(t *testing.T) {
+	tooMany := bson.A{}
+	for i := 0; i < maxExpertiseEntries+1; i++ {
+		tooMany = append(tooMany, "go")
+	}
+
+	tests := []struct {
+		name        string
+		raw         interface{}
+		want        []string
+		wantChanged bool
+	}{
+		{name: "valid list is kept", raw: bson.A{"go", "tech"}, want: []string{"go", "tech"}},
+		{name: "empty list", raw: bson.A{}, want: []string{}},
+		{name: "legacy free-form string", raw: "golang expert", want: []string{}, wantChanged: true},
+		{name: "legacy document", raw: bson.M{"go": true}, want: []string{}, wantChanged: true},
+		{name: "unknown and non-string entries", raw: bson.A{"go", "cobol", 7, "rust"}, want: []string{"go", "rust"}, wantChanged: true},
+		{name: "repeats are dropped", raw: tooMany, want: []string{"go"}, wantChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := cleanExpertise(testTaxonomyNodes(), rawValue(t, tt.raw))
+			if changed != tt.wantChanged {
+				t.Errorf("cleanExpertise() changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cleanExpertise() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanExpertiseLimit(t *testing.T) {
+	nodes := testTaxonomyNodes()
+	stored := bson.A{}
+	want := []string{}
+	for i := 0; i < maxExpertiseEntries+2; i++ {
+		slug := "skill-" + string(rune('a'+i))
+		nodes[slug] = TaxonomyNode{Slug: slug, Level: TaxonomySubcategory, ParentSlug: "software"}
+		stored = append(stored, slug)
+		if i < maxExpertiseEntries {
+			want = append(want, slug)
+		}
+	}
+
+	got, changed := cleanExpertise(nodes, rawValue(t, stored))
+	if !changed {
+		t.Error("cleanExpertise() changed = false, want true")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cleanExpertise() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,385 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	model "Backend-Auth-Profiles/models"
+	"Backend-Auth-Profiles/utils"
+)
+
+const (
+	maxVerificationDocuments = 5
+	// verificationReviewTimeout is how long an admin's claim on an application lasts
+	verificationReviewTimeout = 10 * time.Minute
+)
+
+// VerificationApplication is a user's request for the verified badge
+type VerificationApplication struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     string             `bson:"user_id" json:"user_id"`
+	ProfileID  primitive.ObjectID `bson:"profile_id" json:"profile_id"`
+	Links      []string           `bson:"links" json:"links"`
+	Documents  []string           `bson:"documents" json:"documents"` // S3 keys
+	Status     string             `bson:"status" json:"status"`       // pending, reviewing, approved, rejected
+	Notes      string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	ReviewedBy string             `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	ReviewedAt *time.Time         `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+}
+
+// ReviewVerificationRequest defines the request structure for approving or rejecting an application
+type ReviewVerificationRequest struct {
+	Notes string `json:"notes,omitempty"`
+}
+
+// SubmitVerificationHandler handles POST /profile/verification
+func SubmitVerificationHandler(client *mongo.Client) http.HandlerFunc {
+	awsSession, err := newAWSSession()
+	if err != nil {
+		fmt.Printf("Failed to initialize AWS session: %v\n", err)
+	}
+
+	uploader := s3manager.NewUploader(awsSession)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Parse multipart form (max 20MB)
+		if err := r.ParseMultipartForm(20 << 20); err != nil {
+			writeJSONError(w, "Failed to parse form: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		links := r.MultipartForm.Value["links"]
+		for _, link := range links {
+			if u, err := url.ParseRequestURI(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				writeJSONError(w, "Invalid link: "+link, http.StatusBadRequest)
+				return
+			}
+		}
+		files := r.MultipartForm.File["documents"]
+		if len(links) == 0 && len(files) == 0 {
+			writeJSONError(w, "At least one link or document is required", http.StatusBadRequest)
+			return
+		}
+		if len(files) > maxVerificationDocuments {
+			writeJSONError(w, fmt.Sprintf("At most %d documents are allowed", maxVerificationDocuments), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.Background()
+		user, err := findProfileByUserID(ctx, client, userID)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if user.Verified {
+			writeJSONError(w, "Profile is already verified", http.StatusConflict)
+			return
+		}
+
+		collection := client.Database("authdb").Collection("verification_applications")
+		count, err := collection.CountDocuments(ctx, bson.M{"user_id": userID, "status": bson.M{"$in": []string{"pending", "reviewing"}}})
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if count > 0 {
+			writeJSONError(w, "An application is already pending review", http.StatusConflict)
+			return
+		}
+
+		if uploader == nil {
+			writeJSONError(w, "AWS S3 uploader not initialized", http.StatusInternalServerError)
+			return
+		}
+
+		currentTime := time.Now().Format("20060102150405")
+		documents := []string{}
+		for i, fh := range files {
+			contentType := fh.Header.Get("Content-Type")
+			if !isImage(contentType) && contentType != "application/pdf" {
+				writeJSONError(w, "Invalid document type; must be an image or PDF", http.StatusBadRequest)
+				return
+			}
+
+			file, err := fh.Open()
+			if err != nil {
+				writeJSONError(w, "Failed to read document: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			key := fmt.Sprintf("verification-documents/%s/%s_%d%s", user.ID.Hex(), currentTime, i, path.Ext(fh.Filename))
+			_, err = uploader.Upload(&s3manager.UploadInput{
+				Bucket:      aws.String(os.Getenv("AWS_BUCKET")),
+				Key:         aws.String(key),
+				Body:        file,
+				ACL:         aws.String("private"),
+				ContentType: aws.String(contentType),
+			})
+			file.Close()
+			if err != nil {
+				writeJSONError(w, "Failed to upload to S3: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			documents = append(documents, key)
+		}
+
+		if links == nil {
+			links = []string{}
+		}
+		application := VerificationApplication{
+			UserID:    userID,
+			ProfileID: user.ID,
+			Links:     links,
+			Documents: documents,
+			Status:    "pending",
+			CreatedAt: time.Now(),
+		}
+		res, err := collection.InsertOne(ctx, application)
+		if err != nil {
+			writeJSONError(w, "Failed to submit application", http.StatusInternalServerError)
+			return
+		}
+		application.ID = res.InsertedID.(primitive.ObjectID)
+
+		response := Response{
+			Data:    application,
+			Message: "Verification application submitted",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusCreated)
+	}
+}
+
+// VerificationStatusHandler handles GET /profile/verification and returns the latest application
+func VerificationStatusHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		collection := client.Database("authdb").Collection("verification_applications")
+		var application VerificationApplication
+		err := collection.FindOne(
+			context.Background(),
+			bson.M{"user_id": userID},
+			options.FindOne().SetSort(bson.M{"created_at": -1}),
+		).Decode(&application)
+		if err == mongo.ErrNoDocuments {
+			writeJSONError(w, "No verification application found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		response := Response{
+			Data:    application,
+			Message: "Verification application",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// ListVerificationApplicationsHandler handles GET /admin/verification?status=
+func ListVerificationApplicationsHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := r.URL.Query().Get("status")
+		if status == "" {
+			status = "pending"
+		}
+
+		ctx := context.Background()
+		collection := client.Database("authdb").Collection("verification_applications")
+		cursor, err := collection.Find(ctx, bson.M{"status": status}, options.Find().SetSort(bson.M{"created_at": 1}).SetLimit(100))
+		if err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer cursor.Close(ctx)
+
+		applications := []VerificationApplication{}
+		if err := cursor.All(ctx, &applications); err != nil {
+			writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		response := Response{
+			Data:    map[string]interface{}{"applications": applications},
+			Message: "Verification applications",
+			Status:  true,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// ApproveVerificationHandler handles POST /admin/verification/{id}/approve
+func ApproveVerificationHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reviewVerification(w, r, client, "approved")
+	}
+}
+
+// RejectVerificationHandler handles POST /admin/verification/{id}/reject
+func RejectVerificationHandler(client *mongo.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reviewVerification(w, r, client, "rejected")
+	}
+}
+
+func reviewVerification(w http.ResponseWriter, r *http.Request, client *mongo.Client, decision string) {
+	adminID, _ := r.Context().Value("userID").(string)
+
+	objID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSONError(w, "Invalid id format", http.StatusBadRequest)
+		return
+	}
+
+	// Notes are optional, so an empty body is a decision without notes
+	var req ReviewVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	// Truncated to what Mongo stores, since the claim is matched on reviewing_at
+	now := time.Now().Truncate(time.Millisecond)
+	collection := client.Database("authdb").Collection("verification_applications")
+
+	// Claim the application first so only one admin decides it. A claim left behind by a
+	// crashed request can be taken over once verificationReviewTimeout has passed.
+	var application VerificationApplication
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objID, "$or": bson.A{
+			bson.M{"status": "pending"},
+			bson.M{"status": "reviewing", "reviewing_at": bson.M{"$lt": now.Add(-verificationReviewTimeout)}},
+		}},
+		bson.M{"$set": bson.M{"status": "reviewing", "reviewed_by": adminID, "reviewing_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&application)
+	if err == mongo.ErrNoDocuments {
+		count, countErr := collection.CountDocuments(ctx, bson.M{"_id": objID})
+		if countErr == nil && count > 0 {
+			writeJSONError(w, "Application was already reviewed", http.StatusConflict)
+			return
+		}
+		writeJSONError(w, "Pending application not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	release := func() {
+		if _, err := collection.UpdateOne(ctx,
+			bson.M{"_id": objID, "status": "reviewing", "reviewing_at": now},
+			bson.M{"$set": bson.M{"status": "pending"}, "$unset": bson.M{"reviewed_by": "", "reviewing_at": ""}},
+		); err != nil {
+			log.Printf("Error releasing verification application %s: %v", objID.Hex(), err)
+		}
+	}
+
+	// With the claim held, the badge is granted before the application is finalized, so a
+	// failure never leaves an approved application behind an unverified profile
+	var user model.User
+	profiles := profilesCollection(client)
+	if decision == "approved" {
+		err = profiles.FindOneAndUpdate(ctx,
+			bson.M{"_id": application.ProfileID},
+			bson.M{"$set": bson.M{"verified": true, "verified_at": now, "updated_at": now}},
+		).Decode(&user)
+	} else {
+		err = profiles.FindOne(ctx, bson.M{"_id": application.ProfileID}).Decode(&user)
+	}
+	if err != nil {
+		release()
+		writeJSONError(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objID, "status": "reviewing", "reviewing_at": now},
+		bson.M{
+			"$set": bson.M{
+				"status":      decision,
+				"notes":       req.Notes,
+				"reviewed_by": adminID,
+				"reviewed_at": now,
+			},
+			"$unset": bson.M{"reviewing_at": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&application)
+	if err != nil {
+		// Undo the badge unless the profile already had it, and hand the application back
+		if decision == "approved" && !user.Verified {
+			if _, rollbackErr := profiles.UpdateOne(ctx,
+				bson.M{"_id": application.ProfileID},
+				bson.M{"$set": bson.M{"verified": false, "updated_at": time.Now()}, "$unset": bson.M{"verified_at": ""}},
+			); rollbackErr != nil {
+				log.Printf("Error rolling back verification of %s: %v", application.ProfileID.Hex(), rollbackErr)
+			}
+		}
+		release()
+		writeJSONError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	notification := utils.Notification{
+		UserID:   user.UserID,
+		Email:    user.Email,
+		FCMToken: user.FCMToken,
+		Title:    "Your verification application was " + decision,
+		Body:     req.Notes,
+	}
+	if notification.Body == "" {
+		if decision == "approved" {
+			notification.Body = "Congratulations, your profile now shows the verified badge."
+		} else {
+			notification.Body = "Your application was not approved this time. You can apply again once your profile has more to show."
+		}
+	}
+	go func() {
+		if err := utils.Notify(context.Background(), notification); err != nil {
+			log.Printf("Error sending verification notification: %v", err)
+		}
+	}()
+
+	response := Response{
+		Data:    application,
+		Message: "Application " + decision,
+		Status:  true,
+	}
+	writeJSONResponse(w, response, http.StatusOK)
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -17,6 +19,12 @@ import (
 )
 
 func main() {
+	migrateFollows := flag.Bool("migrate-follows", false, "move legacy follower/following arrays into the follows collection and exit")
+	recountFollows := flag.Bool("recount-follows", false, "recompute follower and following counters from the follows collection and exit")
+	cleanInterests := flag.Bool("clean-interests", false, "drop area_of_interest and area_of_expert entries that do not match the taxonomy and exit")
+	dedupeChannelNames := flag.Bool("dedupe-channel-names", false, "rename profiles whose channel name collides with another, ignoring case, and exit")
+	flag.Parse()
+
 	if err := godotenv.Load(); err != nil {
 		log.Fatal("Error loading .env file")
 	}
@@ -26,25 +34,131 @@ func main() {
 	}
 	defer client.Disconnect(context.Background())
 
+	utils.SetNotifier(utils.NewNotifierFromEnv())
+	utils.SetSessionStore(handler.NewMongoSessionStore(client))
+	accountStatus := handler.NewMongoAccountStatusStore(client)
+	utils.SetAccountStatusChecker(accountStatus)
+	consent := handler.NewMongoConsentChecker(client)
+	taxonomy := handler.NewMongoTaxonomyStore(client)
+	utils.SetConsentChecker(consent)
+	utils.SetMuteChecker(handler.NewMongoMuteChecker(client))
+
+	if *dedupeChannelNames {
+		if err := handler.DedupeChannelNames(client); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := handler.EnsureProfileIndexes(client); err != nil {
+		log.Fatalf("%v; run with -dedupe-channel-names to resolve existing duplicates", err)
+	}
+	handler.EnsureSuggestIndexes(client)
+	handler.EnsureFollowIndexes(client)
+	handler.EnsureRelationIndexes(client)
+	handler.EnsureFollowRequestIndexes(client)
+	handler.EnsureTaxonomyIndexes(client)
+	handler.EnsureExportIndexes(client)
+	if *migrateFollows {
+		if err := handler.MigrateFollowArrays(client); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *recountFollows {
+		if err := handler.RecountFollowCounts(client); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *cleanInterests {
+		if err := handler.CleanInterests(client); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	handler.StartAccountDeletionWorker(client, time.Hour)
+	handler.StartRecommendationWorker(client)
+	handler.StartCompletenessNudgeWorker(client, 24*time.Hour)
+
+	recentAuth := utils.RequireRecentAuth(utils.SensitiveAuthMaxAge)
+	// User-facing routes only accept tokens issued to the apps; admin routes check their own audience
+	appAuth := utils.JWTMiddlewareFor(utils.AppAudiences()...)
+	appLooseAuth := utils.LooseJWTMiddlewareFor(utils.AppAudiences()...)
+
 	router := mux.NewRouter()
 
 	router.HandleFunc("/auth/google", handler.GoogleLoginHandler(client)).Methods("POST")
 	router.HandleFunc("/auth/facebook", handler.FacebookLoginHandler(client)).Methods("POST")
-	router.HandleFunc("/auth/refresh", utils.JWTMiddleware(handler.RefreshTokenHandler)).Methods("POST")
+	router.HandleFunc("/auth/refresh", handler.RefreshTokenHandler).Methods("POST")
+	router.HandleFunc("/auth/reauth", appAuth(handler.ReauthHandler)).Methods("POST")
+	router.HandleFunc("/auth/revoke", handler.RevokeSessionsConfirmHandler).Methods("GET")
+	router.HandleFunc("/auth/revoke", handler.RevokeSessionsHandler).Methods("POST")
+
+	router.HandleFunc("/profile", appAuth(handler.ProfileHandler(client))).Methods("GET")
+	router.HandleFunc("/profile", appAuth(utils.RequireConsent(handler.UpdateProfileHandler(client)))).Methods("PATCH")
+	router.HandleFunc("/profile", appAuth(recentAuth(handler.DeleteAccountHandler(client)))).Methods("DELETE")
+	router.HandleFunc("/profile/deletion/cancel", appAuth(handler.CancelAccountDeletionHandler(client))).Methods("POST")
+	router.HandleFunc("/profile/export", appAuth(handler.RequestDataExportHandler(client))).Methods("POST")
+	router.HandleFunc("/profile/export/{id}", appAuth(handler.DataExportStatusHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/verification", appAuth(utils.RequireConsent(handler.SubmitVerificationHandler(client)))).Methods("POST")
+	router.HandleFunc("/profile/verification", appAuth(handler.VerificationStatusHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/consent", appAuth(handler.AcceptConsentHandler(client, consent))).Methods("POST")
+	router.HandleFunc("/profile/channel-name/available", appLooseAuth(handler.ChannelNameAvailableHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/{id}/follow", appAuth(utils.RequireConsent(handler.FollowHandler(client)))).Methods("POST")
+	router.HandleFunc("/profile/{id}/follow", appAuth(handler.UnfollowHandler(client))).Methods("DELETE")
+	router.HandleFunc("/profile/{id}/followers", appLooseAuth(handler.FollowersHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/{id}/following", appLooseAuth(handler.FollowingHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/{id}/mutual", appAuth(handler.MutualFollowersHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/{id}/block", appAuth(handler.BlockHandler(client))).Methods("POST")
+	router.HandleFunc("/profile/{id}/block", appAuth(handler.UnblockHandler(client))).Methods("DELETE")
+	router.HandleFunc("/profile/{id}/mute", appAuth(handler.MuteHandler(client))).Methods("POST")
+	router.HandleFunc("/profile/{id}/mute", appAuth(handler.UnmuteHandler(client))).Methods("DELETE")
+	router.HandleFunc("/profile/blocks", appAuth(handler.ListRelationsHandler(client, "blocks"))).Methods("GET")
+	router.HandleFunc("/profile/mutes", appAuth(handler.ListRelationsHandler(client, "mutes"))).Methods("GET")
+	router.HandleFunc("/profile/follow-requests", appAuth(handler.FollowRequestsHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/follow-requests/{id}/approve", appAuth(handler.ApproveFollowRequestHandler(client))).Methods("POST")
+	router.HandleFunc("/profile/follow-requests/{id}/reject", appAuth(handler.RejectFollowRequestHandler(client))).Methods("POST")
+	router.HandleFunc("/profile/interests", appAuth(utils.RequireConsent(handler.UpdateInterestsHandler(client)))).Methods("PUT")
+	router.HandleFunc("/profile/expertise", appAuth(utils.RequireConsent(handler.UpdateExpertiseHandler(client)))).Methods("PUT")
+	router.HandleFunc("/profile/privacy", appAuth(handler.UpdatePrivacyHandler(client))).Methods("PUT")
+	router.HandleFunc("/profile/picture", appAuth(utils.RequireConsent(handler.ProfilePictureUploadHandler(client)))).Methods("PUT")
+
+	router.HandleFunc("/onboarding", appAuth(handler.OnboardingHandler(client))).Methods("GET")
+	router.HandleFunc("/onboarding", appAuth(handler.UpdateOnboardingHandler(client))).Methods("PUT")
+	router.HandleFunc("/taxonomy", handler.TaxonomyHandler(taxonomy)).Methods("GET")
+	router.HandleFunc("/legal/current", handler.CurrentLegalDocumentsHandler(consent)).Methods("GET")
+	router.HandleFunc("/public", appLooseAuth(handler.PublicProfileHandler(client))).Methods("GET")
+	router.HandleFunc("/profiles/batch", appLooseAuth(handler.BatchProfilesHandler(client))).Methods("POST")
+	router.HandleFunc("/profile/media", appLooseAuth(handler.PublicProfileMediaHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/media/live", appLooseAuth(handler.PublicProfileMediaLiveHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/media/upcoming", appLooseAuth(handler.PublicProfileMediaUpcomingHandler(client))).Methods("GET")
+	router.HandleFunc("/profile/media2", appLooseAuth(handler.PublicProfileMedia2Handler(client))).Methods("GET")
+
+	router.HandleFunc("/u/{channel_name}", appLooseAuth(handler.ChannelNameResolver(client, handler.PublicProfileHandler(client)))).Methods("GET")
+	router.HandleFunc("/u/{channel_name}/media", appLooseAuth(handler.ChannelNameResolver(client, handler.PublicProfileMediaHandler(client)))).Methods("GET")
+	router.HandleFunc("/u/{channel_name}/media/live", appLooseAuth(handler.ChannelNameResolver(client, handler.PublicProfileMediaLiveHandler(client)))).Methods("GET")
+	router.HandleFunc("/u/{channel_name}/media/upcoming", appLooseAuth(handler.ChannelNameResolver(client, handler.PublicProfileMediaUpcomingHandler(client)))).Methods("GET")
+	router.HandleFunc("/u/{channel_name}/media2", appLooseAuth(handler.ChannelNameResolver(client, handler.PublicProfileMedia2Handler(client)))).Methods("GET")
 
-	router.HandleFunc("/profile", utils.JWTMiddleware(handler.ProfileHandler(client))).Methods("GET")
-	router.HandleFunc("/profile/picture", utils.JWTMiddleware(handler.ProfilePictureUploadHandler(client))).Methods("PUT")
+	router.HandleFunc("/recommendations/profiles", appAuth(handler.RecommendedProfilesHandler(client))).Methods("GET")
+	router.HandleFunc("/search/profiles", appLooseAuth(handler.SearchProfilesHandler(client))).Methods("GET")
+	router.HandleFunc("/search/suggest", appLooseAuth(handler.SuggestProfilesHandler(client))).Methods("GET")
 
-	router.HandleFunc("/public", utils.LooseJWTMiddleware(handler.PublicProfileHandler(client))).Methods("GET")
-	router.HandleFunc("/profile/media", utils.LooseJWTMiddleware(handler.PublicProfileMediaHandler(client))).Methods("GET")
-	router.HandleFunc("/profile/media/live", utils.LooseJWTMiddleware(handler.PublicProfileMediaLiveHandler(client))).Methods("GET")
-	router.HandleFunc("/profile/media/upcoming", utils.LooseJWTMiddleware(handler.PublicProfileMediaUpcomingHandler(client))).Methods("GET")
-	router.HandleFunc("/profile/media2", utils.LooseJWTMiddleware(handler.PublicProfileMedia2Handler(client))).Methods("GET")
+	router.HandleFunc("/admin/users/{id}/suspension", utils.AdminMiddleware(handler.SuspendUserHandler(client, accountStatus))).Methods("POST")
+	router.HandleFunc("/admin/users/{id}/suspension", utils.AdminMiddleware(handler.LiftSuspensionHandler(client, accountStatus))).Methods("DELETE")
+	router.HandleFunc("/admin/verification", utils.AdminMiddleware(handler.ListVerificationApplicationsHandler(client))).Methods("GET")
+	router.HandleFunc("/admin/verification/{id}/approve", utils.AdminMiddleware(handler.ApproveVerificationHandler(client))).Methods("POST")
+	router.HandleFunc("/admin/verification/{id}/reject", utils.AdminMiddleware(handler.RejectVerificationHandler(client))).Methods("POST")
+	router.HandleFunc("/admin/taxonomy", utils.AdminMiddleware(handler.CreateTaxonomyNodeHandler(client, taxonomy))).Methods("POST")
+	router.HandleFunc("/admin/taxonomy/{slug}", utils.AdminMiddleware(handler.UpdateTaxonomyNodeHandler(client, taxonomy))).Methods("PATCH")
+	router.HandleFunc("/admin/taxonomy/{slug}", utils.AdminMiddleware(handler.DeleteTaxonomyNodeHandler(client, taxonomy))).Methods("DELETE")
+	router.HandleFunc("/admin/legal", utils.AdminMiddleware(handler.PublishLegalDocumentHandler(client, consent))).Methods("POST")
 
 	cors := handlers.CORS(
 		handlers.AllowedOrigins([]string{"http://localhost:3000"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
+		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", utils.CSRFHeader}),
+		handlers.AllowCredentials(),
 	)
 
 	port := os.Getenv("PORT")
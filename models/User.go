@@ -11,23 +11,100 @@ type User struct {
     DeviceIDList       []string                 `bson:"device_id_list" json:"device_id_list"`
     Email              string                   `bson:"email" json:"email"`
     ChannelName        string                   `bson:"channel_name" json:"channel_name"`
+    ChannelNameChangedAt *time.Time             `bson:"channel_name_changed_at,omitempty" json:"channel_name_changed_at,omitempty"`
+    ChannelNameNorm    string                   `bson:"channel_name_norm" json:"-"` // lowercase, for prefix search
     AreaOfExpert       []string                 `bson:"area_of_expert" json:"area_of_expert"`
     AreaOfInterest     map[string]map[string][]string `bson:"area_of_interest" json:"area_of_interest"` // branch -> category -> subcategories
     Name               string                   `bson:"name" json:"name"`
+    NameNorm           string                   `bson:"name_norm" json:"-"` // lowercase, for prefix search
     Provider           string                   `bson:"provider" json:"provider"`
     Bio                string                   `bson:"bio" json:"bio"`
     Language           string                   `bson:"language" json:"language"`
     WebAddress         string                   `bson:"web_address" json:"web_address"`
     Location           string                   `bson:"location" json:"location"`
-    Follower           []string                 `bson:"follower" json:"follower"`
-    Following          []string                 `bson:"following" json:"following"`
+    FollowerCount      int64                    `bson:"follower_count" json:"follower_count"` // maintained from the follows collection
+    FollowingCount     int64                    `bson:"following_count" json:"following_count"`
     Verified           bool                     `bson:"verified" json:"verified"`
+    VerifiedAt         *time.Time               `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
     ProfilePicture     string                   `bson:"profile_picture" json:"profile_picture"`
     ProfileOfInterest  []string                 `bson:"profile_of_interest" json:"profile_of_interest"`
     FCMToken           string                   `bson:"fcm_token,omitempty" json:"fcm_token,omitempty"`
+    LastLoginIP        string                   `bson:"last_login_ip,omitempty" json:"-"`
+    LastUserAgent      string                   `bson:"last_user_agent,omitempty" json:"-"`
     CreatedAt          time.Time                `bson:"created_at" json:"created_at"`
     UpdatedAt          time.Time                `bson:"updated_at" json:"updated_at"`
+    Version            int64                    `bson:"version" json:"version"` // Bumped by profile edits only, for optimistic concurrency
     RoomsCreated       int                      `bson:"rooms_created" json:"rooms_created"`
     Live               bool                     `bson:"live" json:"live"`
+    DeletionScheduledAt *time.Time              `bson:"deletion_scheduled_at,omitempty" json:"deletion_scheduled_at,omitempty"`
+    Suspension         *Suspension              `bson:"suspension,omitempty" json:"suspension,omitempty"`
+    Consent            Consent                  `bson:"consent" json:"consent"`
+    Privacy            PrivacySettings          `bson:"privacy" json:"privacy"`
+    Private            bool                     `bson:"private" json:"private"` // follows need approval
+    Onboarding         *Onboarding              `bson:"onboarding,omitempty" json:"onboarding,omitempty"`
+}
+
+// Onboarding tracks the guided setup of a new account
+type Onboarding struct {
+    CompletedSteps []string   `bson:"completed_steps" json:"completed_steps"`
+    SkippedSteps   []string   `bson:"skipped_steps" json:"skipped_steps"`
+    CompletedAt    *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+// Complete reports whether onboarding is finished; profiles created before onboarding existed have none and count as complete
+func (o *Onboarding) Complete() bool {
+    return o == nil || o.CompletedAt != nil
+}
+
+// Visibility levels for PrivacySettings, from least to most restrictive
+const (
+    VisibilityPublic    = "public"
+    VisibilityFollowers = "followers"
+    VisibilityOnlyMe    = "only_me"
+)
+
+// PrivacySettings controls who can see optional parts of a profile; empty values use the defaults
+type PrivacySettings struct {
+    Email       string `bson:"email,omitempty" json:"email"`
+    Location    string `bson:"location,omitempty" json:"location"`
+    WebAddress  string `bson:"web_address,omitempty" json:"web_address"`
+    FollowLists string `bson:"follow_lists,omitempty" json:"follow_lists"`
+    Media       string `bson:"media,omitempty" json:"media"`
+}
+
+// WithDefaults fills unset levels: email stays private unless the user opts in, everything else is public
+func (p PrivacySettings) WithDefaults() PrivacySettings {
+    if p.Email == "" {
+        p.Email = VisibilityOnlyMe
+    }
+    for _, level := range []*string{&p.Location, &p.WebAddress, &p.FollowLists, &p.Media} {
+        if *level == "" {
+            *level = VisibilityPublic
+        }
+    }
+    return p
+}
+
+// Consent records which legal documents the user accepted and their marketing preferences
+type Consent struct {
+    TermsVersion      string     `bson:"terms_version,omitempty" json:"terms_version,omitempty"`
+    TermsAcceptedAt   *time.Time `bson:"terms_accepted_at,omitempty" json:"terms_accepted_at,omitempty"`
+    PrivacyVersion    string     `bson:"privacy_version,omitempty" json:"privacy_version,omitempty"`
+    PrivacyAcceptedAt *time.Time `bson:"privacy_accepted_at,omitempty" json:"privacy_accepted_at,omitempty"`
+    MarketingEmail    bool       `bson:"marketing_email" json:"marketing_email"`
+    MarketingPush     bool       `bson:"marketing_push" json:"marketing_push"`
+}
+
+// Suspension blocks an account from logging in or using its tokens
+type Suspension struct {
+    Type      string     `bson:"type" json:"type"` // suspended or banned
+    Reason    string     `bson:"reason" json:"reason"`
+    ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"` // nil means indefinite
+    CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+}
+
+// Active reports whether the suspension is still in force
+func (s *Suspension) Active() bool {
+    return s != nil && (s.ExpiresAt == nil || time.Now().Before(*s.ExpiresAt))
 }
 
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const defaultIssuer = "backend-auth-profiles"
+
+// TokenIssuer returns the iss claim placed in and expected from every token
+func TokenIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return defaultIssuer
+}
+
+func audiencesFromEnv(key string, defaults ...string) []string {
+	var audiences []string
+	for _, aud := range strings.Split(os.Getenv(key), ",") {
+		if aud = strings.TrimSpace(aud); aud != "" {
+			audiences = append(audiences, aud)
+		}
+	}
+	if len(audiences) == 0 {
+		return defaults
+	}
+	return audiences
+}
+
+// AppAudiences returns the client ids of the user-facing apps, listed in JWT_APP_AUDIENCES
+func AppAudiences() []string {
+	return audiencesFromEnv("JWT_APP_AUDIENCES", "mobile", "web")
+}
+
+// AdminAudiences returns the client ids allowed on admin routes, listed in JWT_ADMIN_AUDIENCES
+func AdminAudiences() []string {
+	return audiencesFromEnv("JWT_ADMIN_AUDIENCES", "admin")
+}
+
+// AllowedAudiences returns every client id tokens may be issued for
+func AllowedAudiences() []string {
+	return append(AppAudiences(), AdminAudiences()...)
+}
+
+// ResolveAudience maps the required client_id of a token request to the audience of the issued tokens
+func ResolveAudience(clientID string) (string, error) {
+	if clientID == "" {
+		return "", fmt.Errorf("client_id is required")
+	}
+	for _, aud := range AllowedAudiences() {
+		if aud == clientID {
+			return aud, nil
+		}
+	}
+	return "", fmt.Errorf("unknown client_id")
+}
+
+// parseToken verifies the signature, expiry, issuer and that aud holds one of audiences.
+// jwt.WithAudience takes a single value, so each accepted audience is tried in turn.
+func parseToken(tokenString string, audiences []string) (*Claims, error) {
+	err := jwt.ErrTokenInvalidAudience
+	for _, aud := range audiences {
+		claims := &Claims{}
+		_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(os.Getenv("JWT_SECRET")), nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(TokenIssuer()), jwt.WithAudience(aud))
+		if err == nil {
+			return claims, nil
+		}
+		if !errors.Is(err, jwt.ErrTokenInvalidAudience) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
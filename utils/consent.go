@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ConsentChecker reports whether a user still has to accept the current terms or privacy policy
+type ConsentChecker interface {
+	ConsentRequired(userID string) (bool, error)
+}
+
+var consentChecker ConsentChecker
+
+// SetConsentChecker configures the checker used when issuing tokens and by RequireConsent
+func SetConsentChecker(c ConsentChecker) {
+	consentChecker = c
+}
+
+// consentRequired reports whether the user still has to accept the current documents.
+// A failed lookup returns ErrAuthUnavailable and reports consent as required.
+func consentRequired(userID string) (bool, error) {
+	if consentChecker == nil {
+		return false, nil
+	}
+	required, err := consentChecker.ConsentRequired(userID)
+	if err != nil {
+		log.Printf("Error checking consent: %v", err)
+		return true, ErrAuthUnavailable
+	}
+	return required, nil
+}
+
+// tokenConsentRequired is the consent_required marker for a new token. When the check fails the
+// marker is set; RequireConsent checks again live, so the user is not held up once it recovers.
+func tokenConsentRequired(userID string) bool {
+	required, _ := consentRequired(userID)
+	return required
+}
+
+// RequireConsent rejects requests from users who have not accepted the current legal documents.
+// It must run inside JWTMiddlewareFor. The check is live so accepting takes effect before tokens are refreshed.
+func RequireConsent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value("userID").(string)
+		required, err := consentRequired(userID)
+		if err != nil {
+			http.Error(w, `{"message": "Unable to verify consent, try again later", "status": false}`, http.StatusServiceUnavailable)
+			return
+		}
+		if required {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"data": {"error": "consent_required"}, "message": "Please accept the latest terms of service and privacy policy", "status": false}`)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
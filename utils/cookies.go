@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	ElevatedCookie     = "elevated_token"
+	CSRFCookie         = "csrf_token"
+	CSRFHeader         = "X-CSRF-Token"
+)
+
+// SetAuthCookies stores the tokens in HttpOnly cookies and issues a fresh CSRF token
+func SetAuthCookies(w http.ResponseWriter, accessToken, refreshToken string) (string, error) {
+	csrfToken, err := generateCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	SetAccessCookie(w, accessToken)
+	http.SetCookie(w, &http.Cookie{
+		Name:     RefreshTokenCookie,
+		Value:    refreshToken,
+		Path:     "/auth/refresh",
+		MaxAge:   int(RefreshTokenTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	// The CSRF cookie is readable by scripts so the client can echo it back in CSRFHeader
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookie,
+		Value:    csrfToken,
+		Path:     "/",
+		MaxAge:   int(RefreshTokenTTL.Seconds()),
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return csrfToken, nil
+}
+
+// SetAccessCookie stores the access token in an HttpOnly cookie
+func SetAccessCookie(w http.ResponseWriter, accessToken string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     AccessTokenCookie,
+		Value:    accessToken,
+		Path:     "/",
+		MaxAge:   int(AccessTokenTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// SetElevatedCookie stores the step-up token from /auth/reauth. It expires with the token, after
+// which requests fall back to the regular access cookie.
+func SetElevatedCookie(w http.ResponseWriter, elevatedToken string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     ElevatedCookie,
+		Value:    elevatedToken,
+		Path:     "/",
+		MaxAge:   int(ElevatedTokenTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// UsesCookieAuth reports whether the request authenticated with the access cookie rather than a bearer token
+func UsesCookieAuth(r *http.Request) bool {
+	_, fromCookie := tokenFromRequest(r)
+	return fromCookie
+}
+
+// ValidCSRF checks the double-submit CSRF token for state-changing requests
+func ValidCSRF(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	cookie, err := r.Cookie(CSRFCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	header := r.Header.Get(CSRFHeader)
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) == 1
+}
+
+// tokenFromRequest returns the access token from the Authorization header or, failing that, the
+// elevated cookie while it lasts and then the access cookie
+func tokenFromRequest(r *http.Request) (string, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer "), false
+	}
+	if cookie, err := r.Cookie(ElevatedCookie); err == nil && cookie.Value != "" {
+		return cookie.Value, true
+	}
+	if cookie, err := r.Cookie(AccessTokenCookie); err == nil && cookie.Value != "" {
+		return cookie.Value, true
+	}
+	return "", false
+}
+
+func generateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate CSRF token: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
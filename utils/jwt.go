@@ -2,10 +2,10 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -18,13 +18,17 @@ const (
 
 // Claims defines the JWT claims structure
 type Claims struct {
-	UserID string `json:"user_id"`
-	Type   string `json:"type"`
+	UserID   string           `json:"user_id"`
+	Type     string           `json:"type"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"` // when the user last signed in with their provider
+	Elevated bool             `json:"elevated,omitempty"`
+	// ConsentRequired tells clients to prompt for the latest terms before calling gated endpoints
+	ConsentRequired bool `json:"consent_required,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateTokens creates access and refresh tokens for a user
-func GenerateTokens(userID string) (string, string, error) {
+// GenerateTokens creates access and refresh tokens for a user, scoped to the given client audience
+func GenerateTokens(userID, audience string) (string, string, error) {
 	jwtKey := os.Getenv("JWT_SECRET")
 	if jwtKey == "" {
 		fmt.Println("JWT_SECRET not set in .env")
@@ -36,11 +40,17 @@ func GenerateTokens(userID string) (string, string, error) {
 		return "", "", fmt.Errorf("userID cannot be empty")
 	}
 	fmt.Printf("Generating tokens for userID: %s\n", userID)
+	authTime := jwt.NewNumericDate(time.Now())
 
 	accessClaims := &Claims{
-		UserID: userID,
-		Type:   "access",
+		UserID:          userID,
+		Type:            "access",
+		AuthTime:        authTime,
+		ConsentRequired: tokenConsentRequired(userID),
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer(),
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{audience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -54,9 +64,13 @@ func GenerateTokens(userID string) (string, string, error) {
 	fmt.Println("Generated Access Token:", accessTokenString)
 
 	refreshClaims := &Claims{
-		UserID: userID,
-		Type:   "refresh",
+		UserID:   userID,
+		Type:     "refresh",
+		AuthTime: authTime,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer(),
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{audience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -80,25 +94,18 @@ func RefreshAccessToken(refreshTokenString string) (string, error) {
 		return "", fmt.Errorf("JWT_SECRET not set")
 	}
 
-	claims := &Claims{}
-
-	token, err := jwt.ParseWithClaims(refreshTokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtKey), nil
-	})
+	// Refresh keeps the audience of the original token, so any known client is accepted here
+	claims, err := parseToken(refreshTokenString, AllowedAudiences())
 	if err != nil {
 		fmt.Printf("Error parsing refresh token: %v\n", err)
-		if err == jwt.ErrTokenMalformed {
+		switch {
+		case errors.Is(err, jwt.ErrTokenMalformed):
 			return "", fmt.Errorf("malformed token")
-		} else if err == jwt.ErrTokenExpired {
+		case errors.Is(err, jwt.ErrTokenExpired):
 			return "", fmt.Errorf("token has expired")
-		} else if err == jwt.ErrTokenSignatureInvalid {
+		case errors.Is(err, jwt.ErrTokenSignatureInvalid):
 			return "", fmt.Errorf("invalid token signature")
 		}
-		return "", fmt.Errorf("failed to parse token: %v", err)
-	}
-
-	if !token.Valid {
-		fmt.Println("Token is not valid")
 		return "", fmt.Errorf("token is not valid")
 	}
 
@@ -112,10 +119,32 @@ func RefreshAccessToken(refreshTokenString string) (string, error) {
 		return "", fmt.Errorf("userID cannot be empty")
 	}
 
+	// Revocation and suspension must also stop refresh, or a stolen refresh token outlives them
+	revoked, err := isSessionRevoked(claims)
+	if err != nil {
+		return "", err
+	}
+	if revoked {
+		return "", fmt.Errorf("session has been revoked")
+	}
+
+	blocked, err := isAccountBlocked(claims)
+	if err != nil {
+		return "", err
+	}
+	if blocked {
+		return "", fmt.Errorf("account is suspended")
+	}
+
 	accessClaims := &Claims{
-		UserID: claims.UserID,
-		Type:   "access",
+		UserID:          claims.UserID,
+		Type:            "access",
+		AuthTime:        claims.AuthTime,
+		ConsentRequired: tokenConsentRequired(claims.UserID),
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer(),
+			Subject:   claims.UserID,
+			Audience:  claims.Audience,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -132,59 +161,105 @@ func RefreshAccessToken(refreshTokenString string) (string, error) {
 	return tokenString, nil
 }
 
-func JWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
+// JWTMiddlewareFor only accepts access tokens whose audience includes one of the given clients.
+// Each route group is wrapped with its own audiences, e.g. AppAudiences or AdminAudiences.
+func JWTMiddlewareFor(audiences ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return jwtMiddleware(next, audiences)
+	}
+}
+
+func jwtMiddleware(next http.HandlerFunc, audiences []string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		tokenString, fromCookie := tokenFromRequest(r)
+		if tokenString == "" {
 			http.Error(w, `{"message": "Missing or invalid Authorization header", "status": false}`, http.StatusUnauthorized)
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims := &Claims{}
+		if fromCookie && !ValidCSRF(r) {
+			http.Error(w, `{"message": "Missing or invalid CSRF token", "status": false}`, http.StatusForbidden)
+			return
+		}
 
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		claims, err := parseToken(tokenString, audiences)
 		if err != nil {
 			fmt.Printf("Error parsing token: %v\n", err)
-			if err == jwt.ErrTokenExpired {
+			switch {
+			case errors.Is(err, jwt.ErrTokenExpired):
 				http.Error(w, `{"message": "Token has expired", "status": false}`, http.StatusUnauthorized)
-				return
+			case errors.Is(err, jwt.ErrTokenInvalidAudience), errors.Is(err, jwt.ErrTokenInvalidIssuer):
+				http.Error(w, `{"message": "Token not issued for this service", "status": false}`, http.StatusUnauthorized)
+			default:
+				http.Error(w, `{"message": "Invalid token", "status": false}`, http.StatusUnauthorized)
 			}
-			http.Error(w, `{"message": "Invalid token", "status": false}`, http.StatusUnauthorized)
 			return
 		}
 
-		if !token.Valid || claims.Type != "access" {
+		if claims.Type != "access" {
 			http.Error(w, `{"message": "Invalid or not an access token", "status": false}`, http.StatusUnauthorized)
 			return
 		}
 
+		if claims.UserID == "" {
+			claims.UserID = claims.Subject
+		}
 		if claims.UserID == "" {
 			http.Error(w, `{"message": "Missing user_id in token", "status": false}`, http.StatusUnauthorized)
 			return
 		}
 
+		revoked, err := isSessionRevoked(claims)
+		if err != nil {
+			http.Error(w, `{"message": "Unable to verify session, try again later", "status": false}`, http.StatusServiceUnavailable)
+			return
+		}
+		if revoked {
+			http.Error(w, `{"message": "Session has been revoked", "status": false}`, http.StatusUnauthorized)
+			return
+		}
+
+		blocked, err := isAccountBlocked(claims)
+		if err != nil {
+			http.Error(w, `{"message": "Unable to verify session, try again later", "status": false}`, http.StatusServiceUnavailable)
+			return
+		}
+		if blocked {
+			http.Error(w, `{"message": "Account is suspended", "status": false}`, http.StatusForbidden)
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), "userID", claims.UserID)
+		if claims.AuthTime != nil {
+			ctx = context.WithValue(ctx, "authTime", claims.AuthTime.Time)
+		}
+		ctx = context.WithValue(ctx, "elevated", claims.Elevated)
+		if len(claims.Audience) > 0 {
+			ctx = context.WithValue(ctx, "audience", claims.Audience[0])
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
-func LooseJWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-		authHeader := r.Header.Get("Authorization")
-		if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			claims := &Claims{}
-
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				return []byte(os.Getenv("JWT_SECRET")), nil
-			})
-			if err == nil && token.Valid && claims.Type == "access" && claims.UserID != "" {
-				ctx = context.WithValue(ctx, "userID", claims.UserID)
+
+// LooseJWTMiddlewareFor identifies the caller when a valid token for one of the given clients is
+// present and lets anonymous requests through otherwise
+func LooseJWTMiddlewareFor(audiences ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			tokenString, fromCookie := tokenFromRequest(r)
+			if tokenString != "" && (!fromCookie || ValidCSRF(r)) {
+				// Anything that cannot be verified, including a failed revocation lookup, stays anonymous
+				claims, err := parseToken(tokenString, audiences)
+				if err == nil && claims.Type == "access" && claims.UserID != "" {
+					revoked, revokedErr := isSessionRevoked(claims)
+					blocked, blockedErr := isAccountBlocked(claims)
+					if revokedErr == nil && blockedErr == nil && !revoked && !blocked {
+						ctx = context.WithValue(ctx, "userID", claims.UserID)
+					}
+				}
 			}
+			next.ServeHTTP(w, r.WithContext(ctx))
 		}
-		next.ServeHTTP(w, r.WithContext(ctx))
 	}
-}
\ No newline at end of file
+}
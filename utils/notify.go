@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"os"
+	"sync"
+)
+
+// Notification is a message delivered to a single user
+type Notification struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email,omitempty"`
+	FCMToken string `json:"fcm_token,omitempty"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	Link     string `json:"link,omitempty"`
+	ActorID  string `json:"actor_id,omitempty"` // Profile id of the user who caused the notification, if any
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// MuteChecker reports whether a recipient has muted or blocked the actor of a notification
+type MuteChecker interface {
+	Muted(ctx context.Context, userID, actorID string) (bool, error)
+}
+
+var notifier Notifier = LogNotifier{}
+
+var muteChecker MuteChecker
+
+// SetNotifier replaces the notifier used by Notify
+func SetNotifier(n Notifier) {
+	notifier = n
+}
+
+// SetMuteChecker configures the checker Notify uses to drop notifications from muted users
+func SetMuteChecker(m MuteChecker) {
+	muteChecker = m
+}
+
+// Notify sends a notification through the configured notifier.
+// Notifications caused by a user the recipient muted or blocked are silently dropped.
+func Notify(ctx context.Context, n Notification) error {
+	if notifier == nil {
+		return fmt.Errorf("notifier not configured")
+	}
+	if n.ActorID != "" && muteChecker != nil {
+		muted, err := muteChecker.Muted(ctx, n.UserID, n.ActorID)
+		if err != nil {
+			return err
+		}
+		if muted {
+			return nil
+		}
+	}
+	return notifier.Notify(ctx, n)
+}
+
+// NewNotifierFromEnv builds a notifier based on the NOTIFIER environment variable.
+// Without one, notifications are only logged; "memory" keeps them for tests and local development.
+func NewNotifierFromEnv() Notifier {
+	switch os.Getenv("NOTIFIER") {
+	case "fcm":
+		return &FCMNotifier{ServerKey: os.Getenv("FCM_SERVER_KEY")}
+	case "email":
+		return &EmailNotifier{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		}
+	case "memory":
+		return NewMemoryNotifier()
+	default:
+		return LogNotifier{}
+	}
+}
+
+// LogNotifier only logs that a notification was sent; it is the default when no channel is configured
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	log.Printf("Notification for %s: %s", n.UserID, n.Title)
+	return nil
+}
+
+// MemoryNotifier keeps notifications in memory, useful for tests and local development
+type MemoryNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (m *MemoryNotifier) Notify(ctx context.Context, n Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, n)
+	return nil
+}
+
+// Sent returns a copy of the notifications delivered so far
+func (m *MemoryNotifier) Sent() []Notification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Notification(nil), m.sent...)
+}
+
+// FCMNotifier sends push notifications through Firebase Cloud Messaging
+type FCMNotifier struct {
+	ServerKey string
+}
+
+func (f *FCMNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.FCMToken == "" {
+		return fmt.Errorf("user has no fcm_token")
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"to": n.FCMToken,
+		"notification": map[string]string{
+			"title": n.Title,
+			"body":  n.Body,
+		},
+		"data": map[string]string{
+			"link": n.Link,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode push payload: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://fcm.googleapis.com/fcm/send", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build push request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "key="+f.ServerKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push notification: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("push notification rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailNotifier sends notifications as plain text emails over SMTP
+type EmailNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.Email == "" {
+		return fmt.Errorf("user has no email")
+	}
+	body := n.Body
+	if n.Link != "" {
+		body += "\n\n" + n.Link
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", e.From, n.Email, n.Title, body)
+	auth := smtp.PlainAuth("", e.Username, e.Password, e.Host)
+	if err := smtp.SendMail(e.Host+":"+e.Port, auth, e.From, []string{n.Email}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	return nil
+}
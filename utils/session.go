@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const RevokeTokenTTL = time.Hour * 24 * 7 // 7 days
+
+// SessionStore records when all sessions of a user were revoked
+type SessionStore interface {
+	RevokedAt(userID string) (time.Time, error)
+	RevokeAll(userID string) error
+}
+
+var sessionStore SessionStore
+
+// ErrAuthUnavailable means a token could not be checked against revocations or account status.
+// Callers answer 503 instead of letting the token through.
+var ErrAuthUnavailable = errors.New("unable to verify session, try again later")
+
+// SetSessionStore configures the store consulted by JWTMiddlewareFor
+func SetSessionStore(s SessionStore) {
+	sessionStore = s
+}
+
+// RevokeAllSessions invalidates every token issued to the user before now
+func RevokeAllSessions(userID string) error {
+	if sessionStore == nil {
+		return fmt.Errorf("session store not configured")
+	}
+	return sessionStore.RevokeAll(userID)
+}
+
+// isSessionRevoked reports whether the token was issued before the user's last revocation.
+// A failed lookup returns ErrAuthUnavailable so revocation keeps working when the store is down.
+func isSessionRevoked(claims *Claims) (bool, error) {
+	if sessionStore == nil || claims.IssuedAt == nil {
+		return false, nil
+	}
+	revokedAt, err := sessionStore.RevokedAt(claims.UserID)
+	if err != nil {
+		log.Printf("Error checking session revocation: %v", err)
+		return false, ErrAuthUnavailable
+	}
+	return !claims.IssuedAt.Time.After(revokedAt), nil
+}
+
+// GenerateRevokeToken creates the token embedded in "this wasn't me" links
+func GenerateRevokeToken(userID string) (string, error) {
+	jwtKey := os.Getenv("JWT_SECRET")
+	if jwtKey == "" {
+		return "", fmt.Errorf("JWT_SECRET not set")
+	}
+
+	claims := &Claims{
+		UserID: userID,
+		Type:   "revoke",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer(),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RevokeTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(jwtKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign revoke token: %v", err)
+	}
+	return tokenString, nil
+}
+
+// ParseRevokeToken validates a revoke token and returns the user it belongs to
+func ParseRevokeToken(tokenString string) (string, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(TokenIssuer()))
+	if err != nil {
+		return "", fmt.Errorf("invalid or expired link")
+	}
+	if claims.Type != "revoke" || claims.UserID == "" {
+		return "", fmt.Errorf("invalid or expired link")
+	}
+	return claims.UserID, nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AccountStatusChecker reports whether a user is currently suspended or banned
+type AccountStatusChecker interface {
+	IsBlocked(userID string) (bool, error)
+}
+
+var accountStatus AccountStatusChecker
+
+// SetAccountStatusChecker configures the checker consulted by JWTMiddlewareFor
+func SetAccountStatusChecker(c AccountStatusChecker) {
+	accountStatus = c
+}
+
+// isAccountBlocked reports whether the token belongs to a suspended or banned user.
+// A failed lookup returns ErrAuthUnavailable rather than treating the account as active.
+func isAccountBlocked(claims *Claims) (bool, error) {
+	if accountStatus == nil {
+		return false, nil
+	}
+	blocked, err := accountStatus.IsBlocked(claims.UserID)
+	if err != nil {
+		log.Printf("Error checking account status: %v", err)
+		return false, ErrAuthUnavailable
+	}
+	return blocked, nil
+}
+
+// IsAdmin reports whether the user is listed in ADMIN_USER_IDS
+func IsAdmin(userID string) bool {
+	for _, id := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if strings.TrimSpace(id) != "" && strings.TrimSpace(id) == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminMiddleware only lets through authenticated users listed in ADMIN_USER_IDS
+func AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return JWTMiddlewareFor(AdminAudiences()...)(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value("userID").(string)
+		if !IsAdmin(userID) {
+			http.Error(w, `{"message": "Admin access required", "status": false}`, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ElevatedTokenTTL = time.Minute * 5
+	// SensitiveAuthMaxAge is how recent a provider login must be for sensitive operations
+	SensitiveAuthMaxAge = time.Minute * 10
+)
+
+// GenerateElevatedToken issues a short-lived access token after the user re-authenticated with their provider
+func GenerateElevatedToken(userID, audience string) (string, error) {
+	jwtKey := os.Getenv("JWT_SECRET")
+	if jwtKey == "" {
+		return "", fmt.Errorf("JWT_SECRET not set")
+	}
+	if userID == "" {
+		return "", fmt.Errorf("userID cannot be empty")
+	}
+
+	now := jwt.NewNumericDate(time.Now())
+	claims := &Claims{
+		UserID:   userID,
+		Type:     "access",
+		AuthTime: now,
+		Elevated: true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer(),
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ElevatedTokenTTL)),
+			IssuedAt:  now,
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(jwtKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign elevated token: %v", err)
+	}
+	return tokenString, nil
+}
+
+// HasRecentAuth reports whether the caller holds an elevated token from /auth/reauth issued within maxAge.
+// Login and refreshed tokens never qualify, since refresh carries auth_time forward.
+// It relies on the elevated and authTime values placed in the context by JWTMiddlewareFor.
+func HasRecentAuth(r *http.Request, maxAge time.Duration) bool {
+	elevated, _ := r.Context().Value("elevated").(bool)
+	authTime, ok := r.Context().Value("authTime").(time.Time)
+	return elevated && ok && time.Since(authTime) <= maxAge
+}
+
+// WriteReauthRequired writes the structured error clients use to trigger /auth/reauth
+func WriteReauthRequired(w http.ResponseWriter, maxAge time.Duration) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	fmt.Fprintf(w, `{"data": {"error": "reauth_required", "max_age": %d}, "message": "Recent authentication required", "status": false}`, int(maxAge.Seconds()))
+}
+
+// RequireRecentAuth rejects requests that do not carry an elevated token obtained within maxAge.
+// It must run inside JWTMiddlewareFor. Clients should react to the reauth_required error by calling /auth/reauth.
+func RequireRecentAuth(maxAge time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !HasRecentAuth(r, maxAge) {
+				WriteReauthRequired(w, maxAge)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+	}
+}